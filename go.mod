@@ -4,5 +4,5 @@ go 1.18
 
 require (
 	github.com/dimchansky/utfbom v1.1.1
-	github.com/gocarina/gocsv v0.0.0-20230616125104-99d496ca653d
+	golang.org/x/text v0.22.0
 )
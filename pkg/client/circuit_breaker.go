@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the endpoint while the circuit breaker of the operation is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+type CircuitBreakerSettings struct {
+	//Number of consecutive failures that opens the circuit
+	FailureThreshold int
+	//How long the circuit stays open before probe requests are let through
+	OpenTimeout time.Duration
+	//Number of successful probe requests in half-open state that close the circuit
+	HalfOpenRequests int
+}
+
+type CircuitBreakerConfig struct {
+	//Settings for operations without an override
+	Default CircuitBreakerSettings
+	//Overrides by operation name, eg. HOTEL_SEARCH_REQUEST
+	Operations map[string]CircuitBreakerSettings
+	//Called on every state transition, after the breaker is unlocked
+	OnStateChange func(operation string, from, to CircuitState)
+}
+
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		Default: CircuitBreakerSettings{
+			FailureThreshold: 5,
+			OpenTimeout:      30 * time.Second,
+			HalfOpenRequests: 1,
+		},
+	}
+}
+
+// WithCircuitBreaker enables a circuit breaker per operation. Transport errors, timeouts and 5xx responses
+// count as failures; while the circuit is open requests fail fast with ErrCircuitOpen. Requests cut by
+// the caller's context and soap:Client faults tell nothing about the endpoint health and are not counted
+func WithCircuitBreaker(config CircuitBreakerConfig) Option {
+	return func(s *goGlobalService) {
+		s.circuitBreaker = newCircuitBreaker(config)
+	}
+}
+
+type circuitBreaker struct {
+	config CircuitBreakerConfig
+
+	mu       sync.Mutex
+	circuits map[goGlobalRequest]*circuit
+	//transitions made under mu, reported by unlock
+	changes []stateChange
+}
+
+type stateChange struct {
+	operation string
+	from      CircuitState
+	to        CircuitState
+}
+
+type circuit struct {
+	settings  CircuitBreakerSettings
+	state     CircuitState
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+}
+
+func newCircuitBreaker(config CircuitBreakerConfig) *circuitBreaker {
+	return &circuitBreaker{
+		config:   config,
+		circuits: map[goGlobalRequest]*circuit{},
+	}
+}
+
+func (b *circuitBreaker) circuit(operation goGlobalRequest) *circuit {
+	c, ok := b.circuits[operation]
+	if !ok {
+		settings, ok := b.config.Operations[string(operation)]
+		if !ok {
+			settings = b.config.Default
+		}
+		if settings.FailureThreshold < 1 {
+			settings.FailureThreshold = 1
+		}
+		if settings.HalfOpenRequests < 1 {
+			settings.HalfOpenRequests = 1
+		}
+		c = &circuit{settings: settings}
+		b.circuits[operation] = c
+	}
+
+	return c
+}
+
+// State returns current state of the operation circuit
+func (b *circuitBreaker) State(operation goGlobalRequest) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(operation)
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= c.settings.OpenTimeout {
+		return CircuitHalfOpen
+	}
+
+	return c.state
+}
+
+// Allow reports whether the request can be sent, in half-open state only a limited number of probes pass
+func (b *circuitBreaker) Allow(operation goGlobalRequest) error {
+	b.mu.Lock()
+	defer b.unlock()
+
+	c := b.circuit(operation)
+	if c.state == CircuitOpen {
+		if time.Since(c.openedAt) < c.settings.OpenTimeout {
+			return fmt.Errorf("%s: %w", operation, ErrCircuitOpen)
+		}
+		b.setState(operation, c, CircuitHalfOpen)
+	}
+
+	if c.state == CircuitHalfOpen {
+		if c.probes >= c.settings.HalfOpenRequests {
+			return fmt.Errorf("%s: %w", operation, ErrCircuitOpen)
+		}
+		c.probes++
+	}
+
+	return nil
+}
+
+// Done records the result of the request allowed by Allow, ctx is the context the request was sent with
+func (b *circuitBreaker) Done(ctx context.Context, operation goGlobalRequest, statusCode int, err error) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	c := b.circuit(operation)
+	if err != nil && isNeutralOutcome(ctx, err) {
+		//the failure count is kept as it is and the probe is given back
+		if c.state == CircuitHalfOpen && c.probes > 0 {
+			c.probes--
+		}
+		return
+	}
+
+	failed := err != nil || statusCode >= http.StatusInternalServerError
+	switch c.state {
+	case CircuitClosed:
+		if !failed {
+			c.failures = 0
+			return
+		}
+		c.failures++
+		if c.failures >= c.settings.FailureThreshold {
+			b.setState(operation, c, CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		if failed {
+			b.setState(operation, c, CircuitOpen)
+			return
+		}
+		c.successes++
+		if c.successes >= c.settings.HalfOpenRequests {
+			b.setState(operation, c, CircuitClosed)
+		}
+	}
+}
+
+func (b *circuitBreaker) setState(operation goGlobalRequest, c *circuit, state CircuitState) {
+	from := c.state
+	c.state = state
+	c.failures = 0
+	c.successes = 0
+	c.probes = 0
+	if state == CircuitOpen {
+		c.openedAt = time.Now()
+	}
+
+	if b.config.OnStateChange != nil && from != state {
+		b.changes = append(b.changes, stateChange{operation: string(operation), from: from, to: state})
+	}
+}
+
+// unlock releases mu and then reports the state changes made under it, so OnStateChange may call the breaker
+func (b *circuitBreaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	for _, change := range changes {
+		b.config.OnStateChange(change.operation, change.from, change.to)
+	}
+}
+
+// isNeutralOutcome reports whether err tells nothing about the endpoint health: the request was cut
+// by the caller's context (cancel or its own deadline), rejected by the rate limiter or was a soap:Client fault.
+// Timeouts of the http client itself still count as failures
+func isNeutralOutcome(ctx context.Context, err error) bool {
+	return ctx.Err() != nil ||
+		isClientFault(err) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, ErrRateLimitExceeded)
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+func testBreaker(threshold int, onStateChange func(string, CircuitState, CircuitState)) *circuitBreaker {
+	return newCircuitBreaker(CircuitBreakerConfig{
+		Default: CircuitBreakerSettings{
+			FailureThreshold: threshold,
+			OpenTimeout:      time.Hour,
+			HalfOpenRequests: 1,
+		},
+		OnStateChange: onStateChange,
+	})
+}
+
+func TestCircuitBreakerNeutralOutcomes(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	transport := &TransportError{Operation: string(searchRequest), Err: context.DeadlineExceeded}
+	clientFault := &models.SoapFault{Code: "soap:Client"}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		err     error
+		neutral bool
+	}{
+		{name: "soap:Client fault", ctx: context.Background(), err: clientFault, neutral: true},
+		{name: "caller deadline", ctx: expired, err: transport, neutral: true},
+		{name: "canceled", ctx: context.Background(), err: context.Canceled, neutral: true},
+		{name: "rate limit", ctx: context.Background(), err: ErrRateLimitExceeded, neutral: true},
+		{name: "http client timeout", ctx: context.Background(), err: transport},
+		{name: "soap:Server fault", ctx: context.Background(), err: &models.SoapFault{Code: "soap:Server"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			breaker := testBreaker(3, nil)
+			for i := 0; i < 2; i++ {
+				breaker.Done(context.Background(), searchRequest, http.StatusBadGateway, nil)
+				breaker.Done(test.ctx, searchRequest, 0, test.err)
+			}
+			if !test.neutral {
+				if state := breaker.State(searchRequest); state != CircuitOpen {
+					t.Errorf("got %s, want %s", state, CircuitOpen)
+				}
+				return
+			}
+
+			//neutral outcomes between failures neither reset nor add to the count
+			if state := breaker.State(searchRequest); state != CircuitClosed {
+				t.Errorf("got %s after two failures, want %s", state, CircuitClosed)
+			}
+			breaker.Done(context.Background(), searchRequest, http.StatusBadGateway, nil)
+			if state := breaker.State(searchRequest); state != CircuitOpen {
+				t.Errorf("got %s after the third failure, want %s", state, CircuitOpen)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	breaker := testBreaker(2, nil)
+	breaker.Done(context.Background(), searchRequest, http.StatusBadGateway, nil)
+	breaker.Done(context.Background(), searchRequest, http.StatusOK, nil)
+	breaker.Done(context.Background(), searchRequest, http.StatusBadGateway, nil)
+
+	if state := breaker.State(searchRequest); state != CircuitClosed {
+		t.Errorf("got %s, want %s", state, CircuitClosed)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	breaker := testBreaker(1, nil)
+	breaker.Done(context.Background(), searchRequest, http.StatusBadGateway, nil)
+	if err := breaker.Allow(searchRequest); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("open circuit allowed the request: %v", err)
+	}
+
+	breaker.circuits[searchRequest].openedAt = time.Now().Add(-2 * time.Hour)
+	if err := breaker.Allow(searchRequest); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	if err := breaker.Allow(searchRequest); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second probe allowed: %v", err)
+	}
+
+	//a cancelled probe gives its slot back
+	breaker.Done(context.Background(), searchRequest, 0, context.Canceled)
+	if err := breaker.Allow(searchRequest); err != nil {
+		t.Fatalf("probe after cancelled one rejected: %v", err)
+	}
+	breaker.Done(context.Background(), searchRequest, http.StatusOK, nil)
+	if state := breaker.State(searchRequest); state != CircuitClosed {
+		t.Errorf("got %s after successful probe, want %s", state, CircuitClosed)
+	}
+}
+
+func TestCircuitBreakerStateChangeCallback(t *testing.T) {
+	var breaker *circuitBreaker
+	var changes []CircuitState
+	breaker = testBreaker(1, func(operation string, from, to CircuitState) {
+		//reading the breaker from the callback must not deadlock
+		changes = append(changes, breaker.State(goGlobalRequest(operation)))
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		breaker.Done(context.Background(), searchRequest, http.StatusBadGateway, nil)
+		breaker.circuits[searchRequest].openedAt = time.Now().Add(-2 * time.Hour)
+		_ = breaker.Allow(searchRequest)
+		breaker.Done(context.Background(), searchRequest, http.StatusOK, nil)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnStateChange deadlocked")
+	}
+
+	want := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(changes) != len(want) {
+		t.Fatalf("got changes %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("got changes %v, want %v", changes, want)
+			break
+		}
+	}
+}
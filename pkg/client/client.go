@@ -1,29 +1,29 @@
 package client
 
 import (
-	"archive/zip"
 	"bytes"
 	"context"
-	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
-	"errors"
-	"fmt"
-	"io"
-	"log"
 	"net/http"
-	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/DmitryKolbin/go-global/pkg/client/models"
-	"github.com/dimchansky/utfbom"
-	"github.com/gocarina/gocsv"
 )
 
 const (
 	getDestinationsUrl = "https://static-data.tourismcloudservice.com/propsdata/Destinations/compress/true"
 	getHotelsUrlFmt    = "https://static-data.tourismcloudservice.com/agency/hotels/%d"
 
+	//static data downloads, used as operation names in logs
+	getDestinationsOperation = "GET_DESTINATIONS"
+	getHotelsOperation       = "GET_HOTELS"
+
+	//parent spans of searches split into several requests
+	searchManyOperation   = "SEARCH_MANY"
+	searchCitiesOperation = "SEARCH_CITIES"
+
 	searchRequest           = goGlobalRequest("HOTEL_SEARCH_REQUEST")
 	bookingValidation       = goGlobalRequest("BOOKING_VALUATION_REQUEST")
 	bookingInsert           = goGlobalRequest("BOOKING_INSERT_REQUEST")
@@ -54,6 +54,20 @@ var requestTypes = map[goGlobalRequest]int64{
 	hotelInfo:               61,
 	priceBreakdown:          14,
 }
+
+// idempotentRequests operations without side effects on the supplier side
+var idempotentRequests = map[goGlobalRequest]bool{
+	searchRequest:           true,
+	bookingValidation:       true,
+	bookingStatus:           true,
+	bookingSearch:           true,
+	advBookingSearch:        true,
+	voucherDetails:          true,
+	bookingInfoForAmendment: true,
+	hotelInfo:               true,
+	priceBreakdown:          true,
+}
+
 var defaultRequestVersion = map[goGlobalRequest]string{
 	searchRequest:     "2.4",
 	bookingValidation: "2.4",
@@ -65,10 +79,25 @@ var defaultRequestVersion = map[goGlobalRequest]string{
 	priceBreakdown:    "2.0",
 }
 
+// searchVersion returns the version search requests are sent with
+func searchVersion(request models.HotelSearchRequest) string {
+	if request.Version == "" {
+		return defaultRequestVersion[searchRequest]
+	}
+
+	return request.Version
+}
+
 type GoGlobalService interface {
 	GetDestinations(context.Context, Credentials) ([]*Destination, error)
 	GetHotels(context.Context, Credentials) ([]*Hotel, error)
+	StreamDestinations(context.Context, Credentials, func(*Destination) error) error
+	StreamHotels(context.Context, Credentials, func(*Hotel) error) error
 	Search(context.Context, Credentials, models.HotelSearchRequest) ([]models.HotelSearchResponseItem, error)
+	SearchDetailed(context.Context, Credentials, models.HotelSearchRequest) (SearchResult, error)
+	SearchStream(context.Context, Credentials, models.HotelSearchRequest, func(*models.HotelSearchResponseItem) error) error
+	SearchMany(context.Context, Credentials, models.HotelSearchRequest, SearchManyConfig) ([]models.HotelSearchResponseItem, models.SearchStats, error)
+	SearchCities(context.Context, Credentials, models.HotelSearchRequest, SearchCitiesConfig) (SearchCitiesResult, error)
 	BookingValuation(context.Context, Credentials, models.BookValuationRequest) (models.BookValuationResponse, error)
 	BookingInsert(context.Context, Credentials, models.BookingInsertRequest) (models.BookingInsertResponse, error)
 	BookingStatus(context.Context, Credentials, models.BookingStatusRequest) (models.BookingStatusResponse, error)
@@ -89,44 +118,42 @@ type Credentials struct {
 }
 
 type goGlobalService struct {
-	baseUrl string
-	client  HttpClient
+	baseUrl        string
+	client         HttpClient
+	retryPolicy    RetryPolicy
+	rateLimiter    *rateLimiter
+	circuitBreaker *circuitBreaker
+	interceptors   []Interceptor
+	logger         Logger
+	metrics        Metrics
+	tracer         Tracer
+	tempDir        string
+
+	dumpRowErrorHandler func(operation string, err *DumpRowError)
 }
 
 func NewGoGlobalService(
 	apiUrl string,
 	client HttpClient,
+	opts ...Option,
 ) GoGlobalService {
-	return &goGlobalService{
+	s := &goGlobalService{
 		baseUrl: apiUrl,
 		client:  client,
 	}
-}
-
-func (c *goGlobalService) GetDestinations(ctx context.Context, credentials Credentials) ([]*Destination, error) {
-	req, err := http.NewRequest(http.MethodGet, getDestinationsUrl, nil)
-	if err != nil {
-		return nil, err
+	for _, opt := range opts {
+		opt(s)
 	}
-	req.SetBasicAuth(credentials.UserName, credentials.Password)
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	defer func() {
-		if err = resp.Body.Close(); err != nil {
-			log.Printf("GetDestinations: close connection: %s \n", err)
-		}
-	}()
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("do request: %v", resp.Status)
-	}
+	return s
+}
 
+func (c *goGlobalService) GetDestinations(ctx context.Context, credentials Credentials) ([]*Destination, error) {
 	var destinations []*Destination
-	err = c.getDumpContent(resp.Body, &destinations)
+	err := c.StreamDestinations(ctx, credentials, func(destination *Destination) error {
+		destinations = append(destinations, destination)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -135,28 +162,11 @@ func (c *goGlobalService) GetDestinations(ctx context.Context, credentials Crede
 }
 
 func (c *goGlobalService) GetHotels(ctx context.Context, credentials Credentials) ([]*Hotel, error) {
-	url := fmt.Sprintf(getHotelsUrlFmt, credentials.AgencyId)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.SetBasicAuth(credentials.UserName, credentials.Password)
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err = resp.Body.Close(); err != nil {
-			log.Printf("GetHotels: close connection: %s \n", err)
-		}
-	}()
-
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("do request: %v", resp.Status)
-	}
 	var hotels []*Hotel
-	err = c.getDumpContent(resp.Body, &hotels)
+	err := c.StreamHotels(ctx, credentials, func(hotel *Hotel) error {
+		hotels = append(hotels, hotel)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -169,26 +179,61 @@ func (c *goGlobalService) Search(
 	credentials Credentials,
 	request models.HotelSearchRequest,
 ) ([]models.HotelSearchResponseItem, error) {
-	if request.Version == "" {
-		request.Version = defaultRequestVersion[searchRequest]
+	results, err := c.search(ctx, credentials, request)
+	if err != nil {
+		return nil, err
 	}
-	results := models.HotelSearchResponse{}
 
-	response, err := c.doRequest(ctx, credentials, searchRequest, request)
+	return results.Hotels, nil
+}
+
+// SearchDetailed is Search returning the response header and request metadata along with the hotels
+func (c *goGlobalService) SearchDetailed(
+	ctx context.Context,
+	credentials Credentials,
+	request models.HotelSearchRequest,
+) (SearchResult, error) {
+	start := time.Now()
+	results, err := c.search(ctx, credentials, request)
 	if err != nil {
-		return nil, err
+		return SearchResult{}, err
 	}
 
-	err = json.Unmarshal(response, &results)
+	return newSearchResult(request, results, time.Since(start)), nil
+}
+
+func (c *goGlobalService) search(
+	ctx context.Context,
+	credentials Credentials,
+	request models.HotelSearchRequest,
+) (results models.HotelSearchResponse, err error) {
+	request.Version = searchVersion(request)
+
+	ctx, span := c.startSpan(ctx, string(searchRequest), credentials, request)
+	start := time.Now()
+	var response []byte
+	defer func() {
+		endSpan(span, nil, err)
+		c.observeRequest(searchRequest, start, len(response), err)
+		if err == nil && c.metrics != nil {
+			c.metrics.ObserveSearch(len(results.Hotels), countOffers(results.Hotels))
+		}
+	}()
+
+	response, err = c.doRequest(ctx, credentials, searchRequest, request)
 	if err != nil {
-		return nil, err
+		return models.HotelSearchResponse{}, err
 	}
 
-	if results.Header.OperationType == models.OperationTypeError || results.Header.OperationType == models.OperationTypeMessage {
-		return nil, results.Main.Error
+	results.Header, err = decodeSearchResponse(bytes.NewReader(response), func(hotel *models.HotelSearchResponseItem) error {
+		results.Hotels = append(results.Hotels, *hotel)
+		return nil
+	})
+	if err != nil {
+		return models.HotelSearchResponse{}, err
 	}
 
-	return results.Hotels, nil
+	return results, nil
 }
 
 func (c *goGlobalService) BookingValuation(
@@ -210,10 +255,6 @@ func (c *goGlobalService) BookingValuation(
 		return r, err
 	}
 
-	if r.Rates.Currency == "" {
-		r.Rates.Currency = r.Rates.CurrencyUpper
-	}
-
 	return r, nil
 }
 
@@ -370,66 +411,66 @@ func (c *goGlobalService) PriceBreakdown(
 	)
 }
 
-func (c *goGlobalService) getDumpContent(compressedDump io.ReadCloser, out any) error {
-	//т.к. дампы небольшие - десяток мегабайт в zip'е и ~ в 3 раза больше в распакованном, то просто загружаем в память
-	buff := bytes.NewBuffer([]byte{})
-	size, err := io.Copy(buff, compressedDump)
+func (c *goGlobalService) SetBaseUrl(url string) {
+	c.baseUrl = url
+}
+
+func (c *goGlobalService) doRequest(
+	ctx context.Context,
+	credentials Credentials,
+	operation goGlobalRequest,
+	request any,
+) ([]byte, error) {
+	payload, err := buildEnvelope(credentials, operation, request)
 	if err != nil {
-		return fmt.Errorf("getDumpContent: iocopy %w", err)
+		return nil, err
 	}
 
-	gocsv.SetCSVReader(func(in io.Reader) gocsv.CSVReader {
-		r := csv.NewReader(in)
-		r.Comma = '|'
-		r.LazyQuotes = true
-		r.TrimLeadingSpace = true
-		r.ReuseRecord = true
-		return r // Allows use pipe as delimiter
+	invoker := chainInterceptors(c.interceptors, func(ctx context.Context, call *Call) ([]byte, int, error) {
+		return c.send(ctx, credentials, operation, restorePassword(call.Envelope, credentials.Password))
 	})
 
-	reader := bytes.NewReader(buff.Bytes())
+	body, status, err := invoker(ctx, &Call{
+		Operation:   string(operation),
+		RequestType: requestTypes[operation],
+		Credentials: credentials.Redacted(),
+		Envelope:    payload,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	zipReader, err := zip.NewReader(reader, size)
-	//у них в дампах всегда один текстовый файл, так что просто читаем и возвращаем содержимое первого
-	for _, file := range zipReader.File {
-		f, err := file.Open()
-		if err != nil {
-			return fmt.Errorf("getDumpContent: file open %w", err)
-		}
-		defer func() {
-			if err = f.Close(); err != nil {
-				log.Printf("getDumpContent: %s \n", err)
-			}
-		}()
-		err = gocsv.Unmarshal(utfbom.SkipOnly(f), out)
-		if err != nil {
-			return err
-		}
+	response := models.EnvelopeResponse{}
 
-		return nil
+	//references to illegal characters like &#x0000; are typed by mistake and dropped by unmarshalXml
+	err = unmarshalXml(string(operation), body, &response)
+
+	//soap faults usually come with 500 status, so the envelope is checked before the status
+	if err == nil && response.Body.Fault != nil {
+		return nil, response.Body.Fault
+	}
+	if status != 0 && (status < http.StatusOK || status >= http.StatusMultipleChoices) {
+		return nil, newStatusError(string(operation), status, body)
+	}
+	if err != nil {
+		return nil, err
 	}
-	return errors.New("getDumpContent: missing files in dump")
-}
 
-func (c *goGlobalService) SetBaseUrl(url string) {
-	c.baseUrl = url
+	return response.Body.MakeRequestResponse.MakeRequestResult.Data, nil
 }
 
-func (c *goGlobalService) doRequest(
-	ctx context.Context,
-	credentials Credentials,
-	operation goGlobalRequest,
-	request any,
-) ([]byte, error) {
+// buildEnvelope marshals SOAP envelope of the request with the redacted password
+func buildEnvelope(credentials Credentials, operation goGlobalRequest, request any) ([]byte, error) {
 	encoded, err := xml.Marshal(request)
 	if err != nil {
 		return nil, err
 	}
+	//real password is put into the envelope by the last invoker, so interceptors never see it
 	requestRoot := models.RequestRoot{
 		Header: models.Header{
 			Agency:        json.Number(strconv.FormatInt(credentials.AgencyId, 10)),
 			User:          credentials.UserName,
-			Password:      credentials.Password,
+			Password:      RedactedPassword,
 			Operation:     string(operation),
 			OperationType: models.OperationTypeRequest,
 		},
@@ -458,9 +499,88 @@ func (c *goGlobalService) doRequest(
 		return nil, err
 	}
 
-	payload = append([]byte(xml.Header), payload...)
+	return append([]byte(xml.Header), payload...), nil
+}
+
+// send posts the envelope to the endpoint, repeating the attempt according to retryPolicy
+func (c *goGlobalService) send(
+	ctx context.Context,
+	credentials Credentials,
+	operation goGlobalRequest,
+	payload []byte,
+) ([]byte, int, error) {
+	for attempt := 1; ; attempt++ {
+		body, status, err := c.sendAttempt(ctx, credentials, operation, payload)
+		if c.retryPolicy == nil {
+			return body, status, err
+		}
+		if err == nil && !isRetryableFailure(status, nil) {
+			c.retryPolicy.OnSuccess(string(operation))
+			return body, status, nil
+		}
+		if !idempotentRequests[operation] && !isNotSentError(err) {
+			return body, status, err
+		}
+
+		backoff, retry := c.retryPolicy.Backoff(string(operation), attempt, status, err)
+		if !retry {
+			return body, status, err
+		}
+		if sleepErr := sleepContext(ctx, backoff); sleepErr != nil {
+			if err == nil {
+				err = sleepErr
+			}
+			return body, status, err
+		}
+	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseUrl, bytes.NewBuffer(payload))
+func (c *goGlobalService) sendAttempt(
+	ctx context.Context,
+	credentials Credentials,
+	operation goGlobalRequest,
+	payload []byte,
+) (body []byte, status int, err error) {
+	if c.circuitBreaker != nil {
+		if err = c.circuitBreaker.Allow(operation); err != nil {
+			return nil, 0, err
+		}
+		defer func() {
+			c.circuitBreaker.Done(ctx, operation, status, err)
+		}()
+	}
+
+	if c.rateLimiter != nil {
+		if err = c.rateLimiter.Wait(ctx, credentials.AgencyId, operation); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	req, err := c.newSoapRequest(ctx, credentials, operation, payload)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	body, status, err = c.client.Send(req)
+	if err != nil {
+		return body, status, &TransportError{Operation: string(operation), Err: err}
+	}
+
+	//soap faults come with 500 status, the ones caused by the request are neither retried nor counted by the breaker
+	if fault := clientFault(operation, status, body); fault != nil {
+		return body, status, fault
+	}
+
+	return body, status, nil
+}
+
+func (c *goGlobalService) newSoapRequest(
+	ctx context.Context,
+	credentials Credentials,
+	operation goGlobalRequest,
+	payload []byte,
+) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseUrl, bytes.NewReader(payload))
 	if err != nil {
 		return nil, err
 	}
@@ -473,23 +593,24 @@ func (c *goGlobalService) doRequest(
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Accept-Encoding", "gzip")
 
-	body, _, err := c.client.Send(req)
-	if err != nil {
-		return nil, err
+	return req, nil
+}
+
+// clientFault returns soap:Client fault of the failed response, nil for other responses
+func clientFault(operation goGlobalRequest, status int, body []byte) *models.SoapFault {
+	if status < http.StatusInternalServerError {
+		return nil
 	}
 
 	response := models.EnvelopeResponse{}
-
-	//assume that data in response contain &#x0000 characters only when it's typed by mistake
-	//and remove them cause it break go xml decoder
-	re := regexp.MustCompile(`&#x[\da-fA-F]+;`)
-	body = []byte(re.ReplaceAllString(string(body), ""))
-	err = xml.Unmarshal(body, &response)
-	if err != nil {
-		return nil, fmt.Errorf("can't parse response: %w", err)
+	if err := unmarshalXml(string(operation), body, &response); err != nil {
+		return nil
+	}
+	if response.Body.Fault == nil || !response.Body.Fault.IsClient() {
+		return nil
 	}
 
-	return response.Body.MakeRequestResponse.MakeRequestResult.Data, err
+	return response.Body.Fault
 }
 
 func genericDoRequest[REQ any, ROOT models.ResponseRoot[RES], RES any](
@@ -498,16 +619,22 @@ func genericDoRequest[REQ any, ROOT models.ResponseRoot[RES], RES any](
 	service *goGlobalService,
 	operation goGlobalRequest,
 	req REQ,
-) (RES, error) {
-	var response RES
+) (response RES, err error) {
+	ctx, span := service.startSpan(ctx, string(operation), credentials, req)
+	start := time.Now()
+	var xmlResponse []byte
+	defer func() {
+		endSpan(span, response, err)
+		service.observeRequest(operation, start, len(xmlResponse), err)
+	}()
 
-	xmlResponse, err := service.doRequest(ctx, credentials, operation, req)
+	xmlResponse, err = service.doRequest(ctx, credentials, operation, req)
 	if err != nil {
 		return response, err
 	}
 
 	var root ROOT
-	err = xml.Unmarshal(xmlResponse, &root)
+	err = unmarshalXml(string(operation), xmlResponse, &root)
 	if err != nil {
 		return response, err
 	}
@@ -519,3 +646,12 @@ func genericDoRequest[REQ any, ROOT models.ResponseRoot[RES], RES any](
 	response = root.GetResponse()
 	return response, nil
 }
+
+func countOffers(hotels []models.HotelSearchResponseItem) int {
+	offers := 0
+	for _, hotel := range hotels {
+		offers += len(hotel.Offers)
+	}
+
+	return offers
+}
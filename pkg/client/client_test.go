@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+// fakeHttpClient answers every request with the same status and body after delay and keeps the sent envelopes
+type fakeHttpClient struct {
+	status int
+	body   string
+	delay  time.Duration
+
+	mu       sync.Mutex
+	requests int
+	sent     []string
+}
+
+func (c *fakeHttpClient) Send(req *http.Request) ([]byte, int, error) {
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	return body, resp.StatusCode, err
+}
+
+func (c *fakeHttpClient) Do(req *http.Request) (*http.Response, error) {
+	payload, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.requests++
+	c.sent = append(c.sent, string(payload))
+	c.mu.Unlock()
+
+	if c.delay > 0 {
+		select {
+		case <-time.After(c.delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	return &http.Response{StatusCode: c.status, Body: io.NopCloser(strings.NewReader(c.body))}, nil
+}
+
+func soapFaultEnvelope(code string) string {
+	return `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault>` +
+		`<faultcode>` + code + `</faultcode><faultstring>Server was unable to read request</faultstring>` +
+		`</soap:Fault></soap:Body></soap:Envelope>`
+}
+
+func TestSoapFaultRetries(t *testing.T) {
+	tests := []struct {
+		code     string
+		requests int
+		state    CircuitState
+	}{
+		{code: "soap:Client", requests: 1, state: CircuitClosed},
+		{code: "soap:Server", requests: 3, state: CircuitOpen},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			httpClient := &fakeHttpClient{status: http.StatusInternalServerError, body: soapFaultEnvelope(test.code)}
+			breakerConfig := DefaultCircuitBreakerConfig()
+			breakerConfig.Default.FailureThreshold = 3
+			retryConfig := DefaultRetryConfig()
+			retryConfig.InitialBackoff = time.Millisecond
+			service := NewGoGlobalService("http://localhost", httpClient,
+				WithRetryPolicy(NewRetryPolicy(retryConfig)),
+				WithCircuitBreaker(breakerConfig),
+			).(*goGlobalService)
+
+			_, err := service.BookingStatus(context.Background(), Credentials{}, models.BookingStatusRequest{})
+
+			var fault *models.SoapFault
+			if !errors.As(err, &fault) || fault.Code != test.code {
+				t.Fatalf("got error %v, want %s fault", err, test.code)
+			}
+			if httpClient.requests != test.requests {
+				t.Errorf("got %d requests, want %d", httpClient.requests, test.requests)
+			}
+			if state := service.circuitBreaker.State(bookingStatus); state != test.state {
+				t.Errorf("got circuit %s, want %s", state, test.state)
+			}
+		})
+	}
+}
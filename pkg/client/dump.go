@@ -0,0 +1,165 @@
+package client
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dimchansky/utfbom"
+)
+
+// WithTempDir sets directory for static data dumps downloaded by GetHotels/StreamHotels and
+// GetDestinations/StreamDestinations, default is os.TempDir()
+func WithTempDir(dir string) Option {
+	return func(s *goGlobalService) {
+		s.tempDir = dir
+	}
+}
+
+// StreamDestinations downloads destinations dump and calls fn for each record without loading the dump into memory.
+// An error returned by fn stops the stream and is returned to the caller
+func (c *goGlobalService) StreamDestinations(
+	ctx context.Context,
+	credentials Credentials,
+	fn func(*Destination) error,
+) (err error) {
+	ctx, span := c.startSpan(ctx, getDestinationsOperation, credentials, nil)
+	defer func() {
+		endSpan(span, nil, err)
+	}()
+
+	return c.downloadDump(ctx, getDestinationsOperation, getDestinationsUrl, credentials, func(dump io.Reader) error {
+		return decodeDump(dump, fn, c.dumpRowErrorReporter(ctx, getDestinationsOperation))
+	})
+}
+
+// StreamHotels downloads agency hotels dump and calls fn for each record without loading the dump into memory.
+// An error returned by fn stops the stream and is returned to the caller
+func (c *goGlobalService) StreamHotels(
+	ctx context.Context,
+	credentials Credentials,
+	fn func(*Hotel) error,
+) (err error) {
+	ctx, span := c.startSpan(ctx, getHotelsOperation, credentials, nil)
+	defer func() {
+		endSpan(span, nil, err)
+	}()
+
+	url := fmt.Sprintf(getHotelsUrlFmt, credentials.AgencyId)
+	return c.downloadDump(ctx, getHotelsOperation, url, credentials, func(dump io.Reader) error {
+		return decodeDump(dump, fn, c.dumpRowErrorReporter(ctx, getHotelsOperation))
+	})
+}
+
+func (c *goGlobalService) dumpRowErrorReporter(ctx context.Context, operation string) func(*DumpRowError) {
+	if c.dumpRowErrorHandler != nil {
+		return func(err *DumpRowError) {
+			c.dumpRowErrorHandler(operation, err)
+		}
+	}
+	if c.logger != nil {
+		return func(err *DumpRowError) {
+			c.logger.ErrorContext(ctx, "go-global dump line skipped", "operation", operation, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// downloadDump spills zipped dump to a temp file and passes content of its single csv file to read
+func (c *goGlobalService) downloadDump(
+	ctx context.Context,
+	operation string,
+	url string,
+	credentials Credentials,
+	read func(io.Reader) error,
+) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(credentials.UserName, credentials.Password)
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logStaticDataRequest(ctx, operation, credentials, start, 0, 0, err)
+		return &TransportError{Operation: operation, Err: err}
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Printf("%s: close connection: %s \n", operation, closeErr)
+		}
+	}()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, statusErrorBodySize))
+		err = newStatusError(operation, resp.StatusCode, body)
+		c.logStaticDataRequest(ctx, operation, credentials, start, resp.StatusCode, resp.ContentLength, err)
+		return err
+	}
+
+	file, err := os.CreateTemp(c.tempDir, "goglobal-dump-*.zip")
+	if err != nil {
+		return fmt.Errorf("%s: create temp file: %w", operation, err)
+	}
+	defer func() {
+		_ = file.Close()
+		if removeErr := os.Remove(file.Name()); removeErr != nil {
+			log.Printf("%s: remove temp file: %s \n", operation, removeErr)
+		}
+	}()
+
+	//body read fails as soon as ctx is cancelled, so the download honors it
+	size, err := io.Copy(file, resp.Body)
+	c.logStaticDataRequest(ctx, operation, credentials, start, resp.StatusCode, size, err)
+	if err != nil {
+		return &TransportError{Operation: operation, Err: err}
+	}
+
+	return readDump(ctx, file, size, read)
+}
+
+func readDump(ctx context.Context, file io.ReaderAt, size int64, read func(io.Reader) error) error {
+	zipReader, err := zip.NewReader(file, size)
+	if err != nil {
+		return fmt.Errorf("getDumpContent: open zip %w", err)
+	}
+
+	//у них в дампах всегда один текстовый файл, так что просто читаем содержимое первого
+	for _, zipFile := range zipReader.File {
+		f, err := zipFile.Open()
+		if err != nil {
+			return fmt.Errorf("getDumpContent: file open %w", err)
+		}
+		defer func() {
+			if err = f.Close(); err != nil {
+				log.Printf("getDumpContent: %s \n", err)
+			}
+		}()
+
+		return read(utfbom.SkipOnly(&contextReader{ctx: ctx, r: f}))
+	}
+
+	return errors.New("getDumpContent: missing files in dump")
+}
+
+// contextReader stops reading once ctx is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.r.Read(p)
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DumpRowError describes a dump line skipped because it could not be decoded
+type DumpRowError struct {
+	//Line number in the dump, header is line 1
+	Line int
+	//Column which value could not be parsed, empty for malformed lines
+	Column string
+	//Raw value of the column
+	Value string
+	Err   error
+}
+
+func (e *DumpRowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("dump line %d: %s", e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("dump line %d: column %s: value %q: %s", e.Line, e.Column, e.Value, e.Err)
+}
+
+func (e *DumpRowError) Unwrap() error {
+	return e.Err
+}
+
+// WithDumpRowErrorHandler sets handler of dump lines skipped by GetHotels/StreamHotels and
+// GetDestinations/StreamDestinations. By default skipped lines are reported to the logger set by WithLogger
+func WithDumpRowErrorHandler(handler func(operation string, err *DumpRowError)) Option {
+	return func(s *goGlobalService) {
+		s.dumpRowErrorHandler = handler
+	}
+}
+
+// decodeDump decodes pipe delimited dump with header into records of T, matching columns by csv tags of T.
+// Columns are matched by name case-insensitively, unknown columns are ignored, missing ones are left empty.
+// Lines that can't be decoded are passed to onRowError and skipped
+func decodeDump[T any](in io.Reader, fn func(*T) error, onRowError func(*DumpRowError)) error {
+	reader := csv.NewReader(in)
+	reader.Comma = '|'
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read dump header: %w", err)
+	}
+
+	fields := dumpFields(reflect.TypeOf((*T)(nil)).Elem())
+	columns := make([]int, len(header))
+	for i, name := range header {
+		index, ok := fields[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			index = -1
+		}
+		columns[i] = index
+	}
+	names := append([]string(nil), header...)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if onRowError != nil {
+				onRowError(&DumpRowError{Line: parseErr.Line, Err: parseErr.Err})
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("read dump: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		item := new(T)
+		value := reflect.ValueOf(item).Elem()
+		var rowErr *DumpRowError
+		for i, raw := range record {
+			if i >= len(columns) || columns[i] < 0 {
+				continue
+			}
+			if err = setDumpField(value.Field(columns[i]), strings.TrimSpace(raw)); err != nil {
+				rowErr = &DumpRowError{Line: line, Column: names[i], Value: raw, Err: err}
+				break
+			}
+		}
+		if rowErr != nil {
+			if onRowError != nil {
+				onRowError(rowErr)
+			}
+			continue
+		}
+
+		if err = fn(item); err != nil {
+			return err
+		}
+	}
+}
+
+// dumpFields returns index of struct fields by lower case csv tag
+func dumpFields(t reflect.Type) map[string]int {
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("csv")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[strings.ToLower(name)] = i
+	}
+
+	return fields
+}
+
+func setDumpField(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+		return nil
+	}
+
+	if raw == "" {
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(strings.Replace(raw, ",", ".", 1), 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(v)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
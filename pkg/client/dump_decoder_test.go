@@ -0,0 +1,154 @@
+package client
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDecodeDump(t *testing.T) {
+	tests := []struct {
+		name   string
+		dump   string
+		hotels []Hotel
+		errors []DumpRowError
+	}{
+		{
+			name:   "empty",
+			dump:   "",
+			hotels: nil,
+		},
+		{
+			name: "header order",
+			dump: "HotelID|Name|CityId|Latitude|Longitude|IsApartment\n" +
+				"10|Hotel A|5|48.85|2.35|true\n",
+			hotels: []Hotel{{HotelID: 10, Name: "Hotel A", CityId: 5, Latitude: 48.85, Longitude: 2.35, IsApartment: true}},
+		},
+		{
+			name: "reordered header",
+			dump: "Longitude|IsApartment|Name|HotelID|Latitude|CityId\n" +
+				"2.35|true|Hotel A|10|48.85|5\n",
+			hotels: []Hotel{{HotelID: 10, Name: "Hotel A", CityId: 5, Latitude: 48.85, Longitude: 2.35, IsApartment: true}},
+		},
+		{
+			name: "header case and spaces",
+			dump: " hotelid | NAME |cityID\n" +
+				"10| Hotel A |5\n",
+			hotels: []Hotel{{HotelID: 10, Name: "Hotel A", CityId: 5}},
+		},
+		{
+			name: "unknown and missing columns",
+			dump: "HotelID|Rating|Name|Chain\n" +
+				"10|4.5|Hotel A|Chain A\n",
+			hotels: []Hotel{{HotelID: 10, Name: "Hotel A"}},
+		},
+		{
+			name: "empty values",
+			dump: "HotelID|Name|CityId|Latitude|IsApartment\n" +
+				"10||||\n",
+			hotels: []Hotel{{HotelID: 10}},
+		},
+		{
+			name: "short and long rows",
+			dump: "HotelID|Name|CityId\n" +
+				"10|Hotel A\n" +
+				"11|Hotel B|5|extra|values\n",
+			hotels: []Hotel{{HotelID: 10, Name: "Hotel A"}, {HotelID: 11, Name: "Hotel B", CityId: 5}},
+		},
+		{
+			name: "decimal comma",
+			dump: "HotelID|Latitude|Longitude\n" +
+				"10|48,85|-2,35\n",
+			hotels: []Hotel{{HotelID: 10, Latitude: 48.85, Longitude: -2.35}},
+		},
+		{
+			name: "malformed values",
+			dump: "HotelID|Name|Latitude|IsApartment\n" +
+				"10|Hotel A|48.85|false\n" +
+				"x11|Hotel B|48.85|false\n" +
+				"12|Hotel C|north|false\n" +
+				"13|Hotel D|48.85|maybe\n" +
+				"14|Hotel E|48.85|1\n",
+			hotels: []Hotel{{HotelID: 10, Name: "Hotel A", Latitude: 48.85}, {HotelID: 14, Name: "Hotel E", Latitude: 48.85, IsApartment: true}},
+			errors: []DumpRowError{
+				{Line: 3, Column: "HotelID", Value: "x11"},
+				{Line: 4, Column: "Latitude", Value: "north"},
+				{Line: 5, Column: "IsApartment", Value: "maybe"},
+			},
+		},
+		{
+			name: "quoting",
+			dump: "HotelID|Name|Address\n" +
+				`10|"Hotel | Spa"|"1 ""Main"" St"` + "\n" +
+				`11|O"Hare Inn|Airport Rd` + "\n" +
+				`12|"Two` + "\n" + `Lines"|x` + "\n" +
+				"x13|Hotel D|y\n",
+			hotels: []Hotel{
+				{HotelID: 10, Name: "Hotel | Spa", Address: `1 "Main" St`},
+				{HotelID: 11, Name: `O"Hare Inn`, Address: "Airport Rd"},
+				{HotelID: 12, Name: "Two\nLines", Address: "x"},
+			},
+			errors: []DumpRowError{{Line: 6, Column: "HotelID", Value: "x13"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var hotels []Hotel
+			var rowErrors []DumpRowError
+			err := decodeDump(strings.NewReader(test.dump), func(hotel *Hotel) error {
+				hotels = append(hotels, *hotel)
+				return nil
+			}, func(err *DumpRowError) {
+				if err.Err == nil {
+					t.Errorf("row error without cause: %v", err)
+				}
+				rowErrors = append(rowErrors, DumpRowError{Line: err.Line, Column: err.Column, Value: err.Value})
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(hotels, test.hotels) {
+				t.Errorf("got hotels %+v, want %+v", hotels, test.hotels)
+			}
+			if !reflect.DeepEqual(rowErrors, test.errors) {
+				t.Errorf("got row errors %+v, want %+v", rowErrors, test.errors)
+			}
+		})
+	}
+}
+
+func TestDecodeDumpRowErrorCause(t *testing.T) {
+	var rowErr *DumpRowError
+	err := decodeDump(strings.NewReader("HotelID\nx1\n"), func(*Hotel) error {
+		return nil
+	}, func(err *DumpRowError) {
+		rowErr = err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rowErr == nil || !errors.Is(rowErr, strconv.ErrSyntax) {
+		t.Fatalf("got row error %v, want strconv.ErrSyntax", rowErr)
+	}
+	if msg := rowErr.Error(); !strings.Contains(msg, "line 2") || !strings.Contains(msg, "HotelID") {
+		t.Errorf("row error message %q misses the line or column", msg)
+	}
+}
+
+func TestDecodeDumpStopsOnCallbackError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := decodeDump(strings.NewReader("HotelID\n1\n2\n3\n"), func(*Hotel) error {
+		calls++
+		return stop
+	}, nil)
+
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("got %v after %d calls, want %v after 1", err, calls, stop)
+	}
+}
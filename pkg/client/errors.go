@@ -0,0 +1,204 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+const (
+	//max length of response body kept in StatusError
+	statusErrorBodySize = 512
+	//number of bytes kept around the offending position in DecodeError
+	decodeErrorSnippetSize = 200
+
+	FormatXml  = "XML"
+	FormatJson = "JSON"
+)
+
+// TransportError - the request did not produce an http response: dns, connect, tls, timeout, etc.
+type TransportError struct {
+	Operation string
+	Err       error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("%s: transport: %s", e.Operation, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError - the endpoint answered with non 2xx status
+type StatusError struct {
+	Operation  string
+	StatusCode int
+	//Beginning of the response body
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: http status %d: %s", e.Operation, e.StatusCode, e.Body)
+}
+
+// DecodeError - the response could not be parsed, Snippet holds the payload around the offending position
+type DecodeError struct {
+	Operation string
+	//FormatXml or FormatJson
+	Format  string
+	Offset  int64
+	Snippet string
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s: can't parse %s response at offset %d: %s, near: %q", e.Operation, e.Format, e.Offset, e.Err, e.Snippet)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func newStatusError(operation string, statusCode int, body []byte) *StatusError {
+	if len(body) > statusErrorBodySize {
+		body = body[:statusErrorBodySize]
+	}
+
+	return &StatusError{
+		Operation:  operation,
+		StatusCode: statusCode,
+		Body:       string(body),
+	}
+}
+
+func newDecodeError(operation string, format string, data []byte, offset int64, err error) *DecodeError {
+	if offset < 0 || offset > int64(len(data)) {
+		offset = 0
+	}
+	from, to := offset-decodeErrorSnippetSize/2, offset+decodeErrorSnippetSize/2
+	if from < 0 {
+		from = 0
+	}
+	if to > int64(len(data)) {
+		to = int64(len(data))
+	}
+
+	return &DecodeError{
+		Operation: operation,
+		Format:    format,
+		Offset:    offset,
+		Snippet:   string(data[from:to]),
+		Err:       err,
+	}
+}
+
+// unmarshalXml decodes data into v skipping references to illegal characters, on failure returns DecodeError
+// pointing to the offending position. The position is in the sanitized data, so it's shifted by skipped references
+func unmarshalXml(operation string, data []byte, v any) error {
+	decoder := xml.NewDecoder(newXmlSanitizer(bytes.NewReader(data)))
+	if err := decoder.Decode(v); err != nil {
+		return newDecodeError(operation, FormatXml, data, decoder.InputOffset(), err)
+	}
+
+	return nil
+}
+
+// unmarshalJson decodes data into v, on failure returns DecodeError pointing to the offending position
+func unmarshalJson(operation string, data []byte, v any) error {
+	err := json.Unmarshal(data, v)
+	if err == nil {
+		return nil
+	}
+
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	} else if errors.As(err, &typeErr) {
+		offset = typeErr.Offset
+	}
+
+	return newDecodeError(operation, FormatJson, data, offset, err)
+}
+
+// errorCode returns supplier error code or the failed layer for errors not reported by the supplier
+func errorCode(err error) string {
+	var goGlobalError models.GoGlobalError
+	var soapFault *models.SoapFault
+	var statusErr *StatusError
+	var decodeErr *DecodeError
+	var transportErr *TransportError
+	switch {
+	case errors.As(err, &goGlobalError):
+		return strconv.FormatInt(goGlobalError.Code, 10)
+	case errors.As(err, &soapFault):
+		return "soap_fault"
+	case errors.As(err, &statusErr):
+		return "http_" + strconv.Itoa(statusErr.StatusCode)
+	case errors.As(err, &decodeErr):
+		return "decode"
+	case errors.As(err, &transportErr):
+		return "transport"
+	}
+
+	return "other"
+}
+
+// IsRetryable reports whether the same request may succeed if sent again later
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var soapFault *models.SoapFault
+	if errors.As(err, &soapFault) {
+		return !soapFault.IsClient()
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError ||
+			statusErr.StatusCode == http.StatusTooManyRequests ||
+			statusErr.StatusCode == http.StatusRequestTimeout
+	}
+
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	return errors.Is(err, models.ErrTimeout) ||
+		errors.Is(err, models.ErrServiceUnavailable) ||
+		errors.Is(err, ErrCircuitOpen) ||
+		errors.Is(err, ErrRateLimitExceeded) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsPermanent reports whether repeating the same request will fail again.
+// A new search, valuation or other caller action is required instead
+func IsPermanent(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var soapFault *models.SoapFault
+	if errors.As(err, &soapFault) {
+		return soapFault.IsClient()
+	}
+
+	return errors.Is(err, models.ErrAuthentication) ||
+		errors.Is(err, models.ErrNoAvailability) ||
+		errors.Is(err, models.ErrPriceChanged) ||
+		errors.Is(err, models.ErrSearchCodeExpired) ||
+		errors.Is(err, models.ErrBookingNotFound) ||
+		errors.Is(err, models.ErrCancellationNotAllowed)
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+)
+
+// RedactedPassword replaces the password in credentials and envelopes passed to interceptors
+const RedactedPassword = "*****"
+
+var redactedPasswordElement = []byte("<Password>" + RedactedPassword + "</Password>")
+
+// Call is a single SOAP call passed through the interceptor chain
+type Call struct {
+	//Operation name, eg. HOTEL_SEARCH_REQUEST
+	Operation string
+	//Request type number sent in MakeRequest
+	RequestType int64
+	//Credentials of the call, Password is always RedactedPassword
+	Credentials Credentials
+	//Marshalled SOAP envelope with redacted password. Interceptors may replace it,
+	//the real password is put back right before sending
+	Envelope []byte
+}
+
+// Invoker sends the call and returns the raw response body and http status code.
+// Status 0 is treated as success, so short-circuiting interceptors may leave it unset
+type Invoker func(ctx context.Context, call *Call) ([]byte, int, error)
+
+// Interceptor wraps every SOAP call. It may inspect or mutate the call, time it, return its own response
+// without calling next (short-circuit) or alter the response returned by next.
+// Retries happen inside next, so one call covers all attempts and the backoff between them
+type Interceptor func(ctx context.Context, call *Call, next Invoker) ([]byte, int, error)
+
+// WithInterceptors appends interceptors to the chain, the first one is the outermost
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(s *goGlobalService) {
+		s.interceptors = append(s.interceptors, interceptors...)
+	}
+}
+
+// Redacted returns a copy of credentials safe to log or pass to third party code
+func (c Credentials) Redacted() Credentials {
+	c.Password = RedactedPassword
+	return c
+}
+
+func chainInterceptors(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, call *Call) ([]byte, int, error) {
+			return interceptor(ctx, call, next)
+		}
+	}
+
+	return invoker
+}
+
+// restorePassword puts the real password into the envelope built with redacted credentials
+func restorePassword(envelope []byte, password string) []byte {
+	escaped := bytes.NewBuffer(nil)
+	escaped.WriteString("<Password>")
+	_ = xml.EscapeText(escaped, []byte(password))
+	escaped.WriteString("</Password>")
+
+	return bytes.Replace(envelope, redactedPasswordElement, escaped.Bytes(), 1)
+}
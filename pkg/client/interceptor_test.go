@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+func TestInterceptorsOrder(t *testing.T) {
+	var trace []string
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, call *Call, next Invoker) ([]byte, int, error) {
+			trace = append(trace, name+" in")
+			body, status, err := next(ctx, call)
+			trace = append(trace, name+" out")
+			return body, status, err
+		}
+	}
+
+	httpClient := &fakeHttpClient{status: http.StatusOK, body: bookingStatusEnvelope()}
+	service := NewGoGlobalService("http://localhost", httpClient, WithInterceptors(record("a"), record("b")))
+	if _, err := service.BookingStatus(context.Background(), Credentials{}, models.BookingStatusRequest{GoBookingCode: "123"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "a in, b in, b out, a out"
+	if got := strings.Join(trace, ", "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestInterceptorCall(t *testing.T) {
+	credentials := Credentials{AgencyId: 1, UserName: "user", Password: "p&ss"}
+	var calls []Call
+	interceptor := func(ctx context.Context, call *Call, next Invoker) ([]byte, int, error) {
+		calls = append(calls, *call)
+		call.Envelope = bytes.Replace(call.Envelope, []byte("GB-7731"), []byte("GB-9900"), 1)
+		return next(ctx, call)
+	}
+
+	httpClient := &fakeHttpClient{status: http.StatusOK, body: bookingStatusEnvelope()}
+	service := NewGoGlobalService("http://localhost", httpClient, WithInterceptors(interceptor))
+	if _, err := service.BookingStatus(context.Background(), credentials, models.BookingStatusRequest{GoBookingCode: "GB-7731"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != 1 {
+		t.Fatalf("got %d calls, want 1", len(calls))
+	}
+	call := calls[0]
+	if call.Operation != string(bookingStatus) || call.RequestType != requestTypes[bookingStatus] {
+		t.Errorf("got operation %s, type %d", call.Operation, call.RequestType)
+	}
+	if call.Credentials != credentials.Redacted() {
+		t.Errorf("got credentials %+v, want the password redacted", call.Credentials)
+	}
+	if bytes.Contains(call.Envelope, []byte("p&amp;ss")) || !bytes.Contains(call.Envelope, redactedPasswordElement) {
+		t.Errorf("interceptor saw the real password: %s", call.Envelope)
+	}
+
+	//the mutated envelope is sent with the real password put back
+	sent := httpClient.sent[0]
+	if !strings.Contains(sent, "GB-9900") || strings.Contains(sent, "GB-7731") {
+		t.Errorf("the mutated envelope was not sent: %s", sent)
+	}
+	if !strings.Contains(sent, "<Password>p&amp;ss</Password>") || strings.Contains(sent, RedactedPassword) {
+		t.Errorf("the real password was not restored: %s", sent)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	cached := func(ctx context.Context, call *Call, next Invoker) ([]byte, int, error) {
+		return []byte(bookingStatusEnvelope()), 0, nil
+	}
+
+	httpClient := &fakeHttpClient{status: http.StatusInternalServerError}
+	service := NewGoGlobalService("http://localhost", httpClient, WithInterceptors(cached))
+	response, err := service.BookingStatus(context.Background(), Credentials{}, models.BookingStatusRequest{GoBookingCode: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.GoBookingCode.Code != "123" || response.GoBookingCode.Status != "C" {
+		t.Errorf("got %+v, want the short-circuit response", response.GoBookingCode)
+	}
+	if httpClient.requests != 0 {
+		t.Errorf("got %d requests, want none", httpClient.requests)
+	}
+}
+
+func TestInterceptorSeesRetriesAsOneCall(t *testing.T) {
+	calls := 0
+	counter := func(ctx context.Context, call *Call, next Invoker) ([]byte, int, error) {
+		calls++
+		return next(ctx, call)
+	}
+
+	unavailable := scriptedResponse{status: http.StatusServiceUnavailable, body: "busy"}
+	httpClient := &scriptedHttpClient{responses: []scriptedResponse{unavailable, unavailable, {status: http.StatusOK, body: bookingStatusEnvelope()}}}
+	config := DefaultRetryConfig()
+	config.InitialBackoff = time.Millisecond
+	service := NewGoGlobalService("http://localhost", httpClient,
+		WithRetryPolicy(NewRetryPolicy(config)),
+		WithInterceptors(counter),
+	)
+	if _, err := service.BookingStatus(context.Background(), Credentials{}, models.BookingStatusRequest{GoBookingCode: "123"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 1 || httpClient.requests != 3 {
+		t.Errorf("got %d interceptor calls for %d requests, want 1 for 3", calls, httpClient.requests)
+	}
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"regexp"
+	"time"
+)
+
+const maskedValue = "***"
+
+// Logger is the subset of *slog.Logger used by the service, so *slog.Logger can be passed as is
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...any)
+	InfoContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+type LoggingConfig struct {
+	//Log request and response bodies at debug level. Passwords, credit card and passenger names are masked
+	LogBodies bool
+	//Max number of logged bytes of each body, 0 - whole body
+	MaxBodySize int
+}
+
+// WithLogger enables structured logging of every call: operation, agency, latency, status and payload sizes
+func WithLogger(logger Logger, config LoggingConfig) Option {
+	return func(s *goGlobalService) {
+		s.logger = logger
+		s.interceptors = append(s.interceptors, LoggingInterceptor(logger, config))
+	}
+}
+
+// LoggingInterceptor logs SOAP calls, use it directly to control its position in the interceptor chain
+func LoggingInterceptor(logger Logger, config LoggingConfig) Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) ([]byte, int, error) {
+		start := time.Now()
+		body, status, err := next(ctx, call)
+		latency := time.Since(start)
+
+		args := []any{
+			"operation", call.Operation,
+			"agency", call.Credentials.AgencyId,
+			"latency", latency,
+			"status", status,
+			"request_size", len(call.Envelope),
+			"response_size", len(body),
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "go-global request failed", append(args, "error", err)...)
+		} else {
+			logger.InfoContext(ctx, "go-global request", args...)
+		}
+
+		if config.LogBodies {
+			logger.DebugContext(ctx, "go-global request body",
+				"operation", call.Operation,
+				"agency", call.Credentials.AgencyId,
+				"request", string(truncateBody(MaskSensitiveData(call.Envelope), config.MaxBodySize)),
+				"response", string(truncateBody(MaskSensitiveData(body), config.MaxBodySize)),
+			)
+		}
+
+		return body, status, err
+	}
+}
+
+func (c *goGlobalService) logStaticDataRequest(
+	ctx context.Context,
+	operation string,
+	credentials Credentials,
+	start time.Time,
+	status int,
+	size int64,
+	err error,
+) {
+	if c.logger == nil {
+		return
+	}
+
+	args := []any{
+		"operation", operation,
+		"agency", credentials.AgencyId,
+		"latency", time.Since(start),
+		"status", status,
+		"response_size", size,
+	}
+	if err != nil {
+		c.logger.ErrorContext(ctx, "go-global request failed", append(args, "error", err)...)
+		return
+	}
+
+	c.logger.InfoContext(ctx, "go-global request", args...)
+}
+
+// sensitiveElements are masked both in plain xml and in xml escaped inside the envelope.
+// Attribute values are matched as quoted strings, so self-closing elements are not taken for opening tags
+var sensitiveElements = func() []*regexp.Regexp {
+	tags := []string{"Password", "PaymentCreditCard", "FirstName", "LastName", "PaxName", "PersonName"}
+	attributes := `(?:\s(?:"[^"]*"|'[^']*'|&quot;.*?&quot;|&#34;.*?&#34;|&apos;.*?&apos;|[^>&/"'])*)?`
+	res := make([]*regexp.Regexp, 0, len(tags))
+	for _, tag := range tags {
+		res = append(res, regexp.MustCompile(`(?s)(<|&lt;)(`+tag+`)(`+attributes+`(?:>|&gt;))(.*?)(<|&lt;)/`+tag+`(>|&gt;)`))
+	}
+	return res
+}()
+
+var sensitiveAttributes = regexp.MustCompile(`\b(FirstName|LastName)=("[^"]*"|'[^']*'|&quot;.*?&quot;|&#34;.*?&#34;)`)
+
+// MaskSensitiveData masks passwords, credit card details and passenger names in request or response body
+func MaskSensitiveData(body []byte) []byte {
+	for _, re := range sensitiveElements {
+		body = re.ReplaceAll(body, []byte("${1}${2}${3}"+maskedValue+"${5}/${2}${6}"))
+	}
+
+	return sensitiveAttributes.ReplaceAll(body, []byte(`${1}="`+maskedValue+`"`))
+}
+
+func truncateBody(body []byte, size int) []byte {
+	if size <= 0 || len(body) <= size {
+		return body
+	}
+
+	return body[:size]
+}
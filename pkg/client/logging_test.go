@@ -0,0 +1,86 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMaskSensitiveData(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		hidden []string
+	}{
+		{
+			name:   "plain elements",
+			body:   `<Password>secret</Password><PaymentCreditCard>4111111111111111</PaymentCreditCard>`,
+			hidden: []string{"secret", "4111111111111111"},
+		},
+		{
+			name:   "escaped element with attributes",
+			body:   `&lt;PersonName PersonID=&quot;1&quot;&gt;JOHN DOE&lt;/PersonName&gt;`,
+			hidden: []string{"JOHN DOE"},
+		},
+		{
+			name:   "element with attributes",
+			body:   `<PersonName PersonID="1" Title='MR'>JOHN DOE</PersonName>`,
+			hidden: []string{"JOHN DOE"},
+		},
+		{
+			name:   "escaped attributes",
+			body:   `&lt;PersonName PersonID=&quot;1&quot; FirstName=&quot;JOHN&quot; LastName=&quot;DOE&quot;/&gt;`,
+			hidden: []string{"JOHN", "DOE"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			masked := string(MaskSensitiveData([]byte(test.body)))
+			for _, value := range test.hidden {
+				if strings.Contains(masked, value) {
+					t.Errorf("%q is not masked in %s", value, masked)
+				}
+			}
+		})
+	}
+}
+
+func TestMaskSensitiveDataSelfClosing(t *testing.T) {
+	body := `<PersonName PersonID="1" FirstName="JOHN" LastName="DOE"/><Remark>late arrival</Remark></PersonName>`
+	masked := string(MaskSensitiveData([]byte(body)))
+	if !strings.Contains(masked, "late arrival") {
+		t.Errorf("self-closing element masked the following content: %s", masked)
+	}
+}
+
+type bodyLogger struct {
+	bytes.Buffer
+}
+
+func (l *bodyLogger) DebugContext(_ context.Context, _ string, args ...any) { fmt.Fprint(l, args...) }
+func (l *bodyLogger) InfoContext(context.Context, string, ...any)           {}
+func (l *bodyLogger) ErrorContext(context.Context, string, ...any)          {}
+
+func TestLoggingInterceptorMasksBeforeTruncating(t *testing.T) {
+	envelope := `&lt;PaymentCreditCard&gt;4111111111111111&lt;/PaymentCreditCard&gt;` +
+		`&lt;PersonName PersonID=&quot;1&quot; FirstName=&quot;JOHNATHAN&quot; LastName=&quot;DOE&quot;/&gt;`
+	response := `<PaxName>JOHNATHAN DOE</PaxName>`
+
+	for size := 1; size <= len(envelope); size++ {
+		logger := &bodyLogger{}
+		interceptor := LoggingInterceptor(logger, LoggingConfig{LogBodies: true, MaxBodySize: size})
+		_, _, _ = interceptor(context.Background(), &Call{Envelope: []byte(envelope)}, func(context.Context, *Call) ([]byte, int, error) {
+			return []byte(response), 200, nil
+		})
+
+		logged := logger.String()
+		for _, value := range []string{"4111", "JOHN"} {
+			if strings.Contains(logged, value) {
+				t.Fatalf("max body size %d: %q is logged: %s", size, value, logged)
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"time"
+)
+
+// Metrics receives measurements of supplier calls, see PrometheusMetrics for the bundled implementation
+type Metrics interface {
+	// ObserveRequest is called once per service method call of a SOAP operation, err is the error returned to the caller
+	ObserveRequest(operation string, latency time.Duration, responseSize int, err error)
+	// ObserveSearch is called after each successful Search with the number of returned hotels and offers
+	ObserveSearch(hotels int, offers int)
+}
+
+// WithMetrics enables reporting of request counts, errors, latencies and response sizes
+func WithMetrics(metrics Metrics) Option {
+	return func(s *goGlobalService) {
+		s.metrics = metrics
+	}
+}
+
+func (c *goGlobalService) observeRequest(operation goGlobalRequest, start time.Time, responseSize int, err error) {
+	if c.metrics == nil {
+		return
+	}
+
+	c.metrics.ObserveRequest(string(operation), time.Since(start), responseSize, err)
+}
@@ -0,0 +1,211 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	DefaultLatencyBuckets      = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60}
+	DefaultResponseSizeBuckets = []float64{1 << 10, 8 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20, 64 << 20}
+	DefaultSearchCountBuckets  = []float64{0, 1, 5, 10, 50, 100, 500, 1000, 5000}
+)
+
+// PrometheusMetrics collects Metrics in memory and renders them in the Prometheus text exposition format.
+// It implements http.Handler, so it can be mounted as is, eg. http.Handle("/metrics", metrics)
+type PrometheusMetrics struct {
+	namespace string
+
+	mu           sync.Mutex
+	requests     map[string]float64
+	errors       map[[2]string]float64
+	latency      map[string]*histogram
+	responseSize map[string]*histogram
+	hotels       *histogram
+	offers       *histogram
+}
+
+func NewPrometheusMetrics(namespace string) *PrometheusMetrics {
+	if namespace == "" {
+		namespace = "goglobal"
+	}
+
+	return &PrometheusMetrics{
+		namespace:    namespace,
+		requests:     map[string]float64{},
+		errors:       map[[2]string]float64{},
+		latency:      map[string]*histogram{},
+		responseSize: map[string]*histogram{},
+		hotels:       newHistogram(DefaultSearchCountBuckets),
+		offers:       newHistogram(DefaultSearchCountBuckets),
+	}
+}
+
+func (m *PrometheusMetrics) ObserveRequest(operation string, latency time.Duration, responseSize int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[operation]++
+	if err != nil {
+		m.errors[[2]string{operation, errorCode(err)}]++
+	}
+
+	if _, ok := m.latency[operation]; !ok {
+		m.latency[operation] = newHistogram(DefaultLatencyBuckets)
+		m.responseSize[operation] = newHistogram(DefaultResponseSizeBuckets)
+	}
+	m.latency[operation].observe(latency.Seconds())
+	m.responseSize[operation].observe(float64(responseSize))
+}
+
+func (m *PrometheusMetrics) ObserveSearch(hotels int, offers int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hotels.observe(float64(hotels))
+	m.offers.observe(float64(offers))
+}
+
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	out := bufio.NewWriter(w)
+	m.writeTo(out)
+	_ = out.Flush()
+}
+
+func (m *PrometheusMetrics) writeTo(w *bufio.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := m.namespace + "_requests_total"
+	writeMetricHeader(w, name, "Number of requests by operation", "counter")
+	for _, operation := range sortedKeys(m.requests) {
+		writeSample(w, name, labels("operation", operation), m.requests[operation])
+	}
+
+	name = m.namespace + "_request_errors_total"
+	writeMetricHeader(w, name, "Number of failed requests by operation and supplier error code", "counter")
+	errorKeys := make([][2]string, 0, len(m.errors))
+	for key := range m.errors {
+		errorKeys = append(errorKeys, key)
+	}
+	sort.Slice(errorKeys, func(i, j int) bool {
+		if errorKeys[i][0] != errorKeys[j][0] {
+			return errorKeys[i][0] < errorKeys[j][0]
+		}
+		return errorKeys[i][1] < errorKeys[j][1]
+	})
+	for _, key := range errorKeys {
+		writeSample(w, name, labels("operation", key[0], "code", key[1]), m.errors[key])
+	}
+
+	name = m.namespace + "_request_duration_seconds"
+	writeMetricHeader(w, name, "Request latency by operation", "histogram")
+	for _, operation := range sortedKeys(m.latency) {
+		m.latency[operation].writeTo(w, name, "operation", operation)
+	}
+
+	name = m.namespace + "_response_size_bytes"
+	writeMetricHeader(w, name, "Response size by operation", "histogram")
+	for _, operation := range sortedKeys(m.responseSize) {
+		m.responseSize[operation].writeTo(w, name, "operation", operation)
+	}
+
+	name = m.namespace + "_search_hotels"
+	writeMetricHeader(w, name, "Number of hotels returned by search", "histogram")
+	m.hotels.writeTo(w, name)
+
+	name = m.namespace + "_search_offers"
+	writeMetricHeader(w, name, "Number of offers returned by search", "histogram")
+	m.offers.writeTo(w, name)
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []float64
+	sum     float64
+	count   float64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{
+		buckets: buckets,
+		counts:  make([]float64, len(buckets)),
+	}
+}
+
+func (h *histogram) observe(value float64) {
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+func (h *histogram) writeTo(w *bufio.Writer, name string, labelPairs ...string) {
+	for i, bound := range h.buckets {
+		writeSample(w, name+"_bucket", labels(append(labelPairs, "le", formatFloat(bound))...), h.counts[i])
+	}
+	writeSample(w, name+"_bucket", labels(append(labelPairs, "le", "+Inf")...), h.count)
+	writeSample(w, name+"_sum", labels(labelPairs...), h.sum)
+	writeSample(w, name+"_count", labels(labelPairs...), h.count)
+}
+
+func writeMetricHeader(w *bufio.Writer, name, help, metricType string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeSample(w *bufio.Writer, name, labels string, value float64) {
+	_, _ = fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(value))
+}
+
+func labels(pairs ...string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	b := strings.Builder{}
+	b.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelValueReplacer.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
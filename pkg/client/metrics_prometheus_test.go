@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+func renderMetrics(t *testing.T, metrics *PrometheusMetrics) string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("got content type %q", contentType)
+	}
+
+	return recorder.Body.String()
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	metrics := NewPrometheusMetrics("test")
+	metrics.ObserveRequest("SEARCH", 250*time.Millisecond, 2048, nil)
+	metrics.ObserveRequest("SEARCH", 2*time.Second, 100, newStatusError("SEARCH", http.StatusServiceUnavailable, nil))
+	metrics.ObserveRequest("SEARCH", 0, 0, models.GoGlobalError{Code: 301, Message: "Login failed"})
+	metrics.ObserveRequest("STATUS", 10*time.Millisecond, 0, errors.New("unknown"))
+	metrics.ObserveRequest(`a"b\`, time.Millisecond, 0, nil)
+	metrics.ObserveSearch(3, 7)
+
+	out := renderMetrics(t, metrics)
+
+	for _, line := range []string{
+		"# HELP test_requests_total Number of requests by operation",
+		"# TYPE test_requests_total counter",
+		`test_requests_total{operation="SEARCH"} 3`,
+		`test_requests_total{operation="STATUS"} 1`,
+		`test_requests_total{operation="a\"b\\"} 1`,
+		"# TYPE test_request_errors_total counter",
+		`test_request_errors_total{operation="SEARCH",code="301"} 1`,
+		`test_request_errors_total{operation="SEARCH",code="http_503"} 1`,
+		`test_request_errors_total{operation="STATUS",code="other"} 1`,
+		"# TYPE test_request_duration_seconds histogram",
+		`test_request_duration_seconds_bucket{operation="SEARCH",le="0.25"} 2`,
+		`test_request_duration_seconds_bucket{operation="SEARCH",le="0.5"} 2`,
+		`test_request_duration_seconds_bucket{operation="SEARCH",le="2.5"} 3`,
+		`test_request_duration_seconds_bucket{operation="SEARCH",le="+Inf"} 3`,
+		`test_request_duration_seconds_sum{operation="SEARCH"} 2.25`,
+		`test_request_duration_seconds_count{operation="SEARCH"} 3`,
+		`test_response_size_bytes_bucket{operation="SEARCH",le="1024"} 2`,
+		`test_response_size_bytes_bucket{operation="SEARCH",le="8192"} 3`,
+		`test_response_size_bytes_sum{operation="SEARCH"} 2148`,
+		`test_search_hotels_bucket{le="1"} 0`,
+		`test_search_hotels_bucket{le="5"} 1`,
+		`test_search_offers_bucket{le="5"} 0`,
+		`test_search_offers_bucket{le="10"} 1`,
+		`test_search_offers_sum 7`,
+		`test_search_offers_count 1`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing %q in\n%s", line, out)
+		}
+	}
+
+	//successful requests are not counted as errors
+	if strings.Contains(out, `test_request_errors_total{operation="a\"b\\"`) {
+		t.Error("successful request was counted as error")
+	}
+	//samples are rendered in a stable order
+	if out != renderMetrics(t, metrics) {
+		t.Error("output changed between renders")
+	}
+}
+
+func TestPrometheusMetricsDefaultNamespace(t *testing.T) {
+	out := renderMetrics(t, NewPrometheusMetrics(""))
+
+	if !strings.Contains(out, "# TYPE goglobal_requests_total counter\n") || !strings.Contains(out, "goglobal_search_hotels_count 0\n") {
+		t.Errorf("got\n%s", out)
+	}
+}
+
+func TestServiceMetrics(t *testing.T) {
+	metrics := NewPrometheusMetrics("test")
+	httpClient := &scriptedHttpClient{responses: []scriptedResponse{
+		{status: http.StatusOK, body: bookingStatusEnvelope()},
+		{status: http.StatusBadGateway, body: "bad gateway"},
+	}}
+	service := NewGoGlobalService("http://localhost", httpClient, WithMetrics(metrics))
+
+	for i := 0; i < 2; i++ {
+		_, _ = service.BookingStatus(context.Background(), Credentials{}, models.BookingStatusRequest{GoBookingCode: "123"})
+	}
+
+	out := renderMetrics(t, metrics)
+	operation := string(bookingStatus)
+	for _, line := range []string{
+		`test_requests_total{operation="` + operation + `"} 2`,
+		`test_request_errors_total{operation="` + operation + `",code="http_502"} 1`,
+		`test_request_duration_seconds_count{operation="` + operation + `"} 2`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing %q in\n%s", line, out)
+		}
+	}
+}
@@ -21,17 +21,17 @@ type AdvBookingSearchRequest struct {
 	//Code of the city
 	CityCode int64 `xml:"CityCode,omitempty"`
 	//Earliest arrival date (YYYY-mm-dd)
-	ArrivalDateRangeFrom string `xml:"ArrivalDateRangeFrom,omitempty"`
+	ArrivalDateRangeFrom Date `xml:"ArrivalDateRangeFrom,omitempty"`
 	//Latest arrival date (YYYY-mm-dd)
-	ArrivalDateRangeTo string `xml:"ArrivalDateRangeTo,omitempty"`
+	ArrivalDateRangeTo Date `xml:"ArrivalDateRangeTo,omitempty"`
 	//Match exact date (deprecated)	(YYYY-mm-dd)
-	ArrivalDate string `xml:"ArrivalDate,omitempty"`
+	ArrivalDate Date `xml:"ArrivalDate,omitempty"`
 	//Single Date of booking creation (YYYY-mm-dd)
-	CreatedDate string `xml:"CreatedDate,omitempty"`
+	CreatedDate Date `xml:"CreatedDate,omitempty"`
 	//Earliest Date of booking creation	(YYYY-mm-dd)
-	CreatedDateRangeFrom string `xml:"CreatedDateRangeFrom,omitempty"`
+	CreatedDateRangeFrom Date `xml:"CreatedDateRangeFrom,omitempty"`
 	//Latest Date of booking creation (YYYY-mm-dd)
-	CreatedDateRangeTo string `xml:"CreatedDateRangeTo,omitempty"`
+	CreatedDateRangeTo Date `xml:"CreatedDateRangeTo,omitempty"`
 	//Agent Ref as provided in the booking request
 	ClientBookingCode string `xml:"ClientBookingCode,omitempty"`
 	//No. of nights
@@ -50,14 +50,23 @@ type AdvBookingSearchRoot struct {
 
 func (r AdvBookingSearchRoot) CheckError() error {
 	if r.Header.OperationType == OperationTypeError || r.Header.OperationType == OperationTypeMessage {
-		return r.Main.ErrorResponse.Error
+		return NewSupplierError(r.Main.ErrorResponse)
 	}
 
 	return nil
 }
 
 func (r AdvBookingSearchRoot) GetResponse() AdvBookingSearchResponse {
-	return r.Main.Bookings
+	bookings := r.Main.Bookings
+	bookings.Booking = append([]AdvBookingSearchBooking(nil), bookings.Booking...)
+	for i := range bookings.Booking {
+		booking := &bookings.Booking[i]
+		booking.TotalPrice.withCurrency(booking.Currency)
+		booking.GrossPrice.Value.withCurrency(booking.GrossPrice.Currency)
+		booking.Commission.Value.withCurrency(booking.Currency)
+	}
+
+	return bookings
 }
 
 type AdvBookingSearchMainResponse struct {
@@ -81,7 +90,7 @@ type AdvBookingSearchBooking struct {
 	//The client booking code
 	ClientBookingCode string `xml:"ClientBookingCode"`
 	//Date of creation (yyyy-MM-dd HH:mm)
-	CreatedDate string `xml:"CreatedDate"`
+	CreatedDate DateTime `xml:"CreatedDate"`
 	//Id of Agency - usually same as credentials
 	AgencyID int64 `xml:"AgencyID"`
 	//Name of agency - usually belonging to the credentials
@@ -89,7 +98,7 @@ type AdvBookingSearchBooking struct {
 	//The status of the booking RQ, X, C etc.
 	BookingStatus string `xml:"BookingStatus"`
 	//The total client price
-	TotalPrice float64 `xml:"TotalPrice"`
+	TotalPrice Money `xml:"TotalPrice"`
 	//Currency
 	Currency string `xml:"Currency"`
 	//The total agent price
@@ -107,7 +116,7 @@ type AdvBookingSearchBooking struct {
 	//BoardBasis
 	RoomBasis string `xml:"RoomBasis,omitempty"`
 	//Check-in Date (yyyy-MM-dd)
-	ArrivalDate string `xml:"ArrivalDate,omitempty"`
+	ArrivalDate Date `xml:"ArrivalDate,omitempty"`
 	//Transfer Country
 	Country string `xml:"Country,omitempty"`
 	//Description of Transfer
@@ -117,9 +126,9 @@ type AdvBookingSearchBooking struct {
 	//Location of Dropoff
 	DropOffLocation string `xml:"DropOffLocation,omitempty"`
 	//Pickup Date and time (yyyy-MM-dd HH:mm)
-	PickupDate string `xml:"PickupDate,omitempty"`
+	PickupDate DateTime `xml:"PickupDate,omitempty"`
 	//Cancellaton deadline date
-	CancellationDeadline string `xml:"CancellationDeadline"`
+	CancellationDeadline Date `xml:"CancellationDeadline"`
 	//Number of nights
 	Nights int64 `xml:"Nights,omitempty"`
 	//No Alternative Hotel Returned
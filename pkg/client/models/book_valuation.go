@@ -11,7 +11,7 @@ type BookValuationRequest struct {
 	//The Hotel Search Code
 	HotelSearchCode string `xml:"HotelSearchCode"`
 	//Check-in Date (yyyy-MM-dd)
-	ArrivalDate string `xml:"ArrivalDate"`
+	ArrivalDate Date `xml:"ArrivalDate"`
 	//Attribute to request TotalTax and RoomRate when available - default false
 	ReturnTaxData bool `xml:"ReturnTaxData,attr,omitempty"`
 }
@@ -24,14 +24,22 @@ type BookValuationRoot struct {
 
 func (r BookValuationRoot) CheckError() error {
 	if r.Header.OperationType == OperationTypeError || r.Header.OperationType == OperationTypeMessage {
-		return r.Main.ErrorResponse.Error
+		return NewSupplierError(r.Main.ErrorResponse)
 	}
 
 	return nil
 }
 
 func (r BookValuationRoot) GetResponse() BookValuationResponse {
-	return r.Main.BookValuationResponse
+	response := r.Main.BookValuationResponse
+	if response.Rates.Currency == "" {
+		response.Rates.Currency = response.Rates.CurrencyUpper
+	}
+	response.Rates.Value.withCurrency(response.Rates.Currency)
+	response.TotalTax.withCurrency(response.Rates.Currency)
+	response.RoomRate.withCurrency(response.Rates.Currency)
+
+	return response
 }
 
 type BookValuationMainResponse struct {
@@ -48,12 +56,12 @@ type CancellationPolicies struct {
 
 type BookValuationResponse struct {
 	HotelSearchCode      string               `xml:"HotelSearchCode"`
-	ArrivalDate          string               `xml:"ArrivalDate"`
-	CancellationDeadline string               `xml:"CancellationDeadline"`
+	ArrivalDate          Date                 `xml:"ArrivalDate"`
+	CancellationDeadline Date                 `xml:"CancellationDeadline"`
 	Remarks              string               `xml:"Remarks"`
 	Rates                BookValuationRate    `xml:"Rates"`
-	TotalTax             float64              `xml:"TotalTax"`
-	RoomRate             float64              `xml:"RoomRate"`
+	TotalTax             Money                `xml:"TotalTax"`
+	RoomRate             Money                `xml:"RoomRate"`
 	CancellationPolicies CancellationPolicies `xml:"CancellationPolicies"`
 }
 
@@ -61,5 +69,5 @@ type BookValuationRate struct {
 	XMLName       xml.Name `xml:"Rates"`
 	Currency      string   `xml:"currency,attr"`
 	CurrencyUpper string   `xml:"Currency,attr"` // in docs currency attribute is lower case. this is fallback for some cases
-	Value         float64  `xml:",chardata"`
+	Value         Money    `xml:",chardata"`
 }
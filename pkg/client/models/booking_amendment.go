@@ -7,7 +7,7 @@ import (
 type BookingAmendmentRequest struct {
 	XMLName xml.Name `xml:"Main"`
 	//Check In Date	2013-10-08
-	ArrivalDate string `xml:"ArrivalDate"`
+	ArrivalDate Date `xml:"ArrivalDate"`
 	//Number of nights
 	Nights int64 `xml:"Nights"`
 	//Room List
@@ -24,7 +24,7 @@ type BookingAmendmentRoot struct {
 
 func (r BookingAmendmentRoot) CheckError() error {
 	if r.Header.OperationType == OperationTypeError || r.Header.OperationType == OperationTypeMessage {
-		return r.Main.ErrorResponse.Error
+		return NewSupplierError(r.Main.ErrorResponse)
 	}
 
 	return nil
@@ -18,7 +18,7 @@ type BookingCancelRoot struct {
 
 func (r BookingCancelRoot) CheckError() error {
 	if r.Header.OperationType == OperationTypeError || r.Header.OperationType == OperationTypeMessage {
-		return r.Main.ErrorResponse.Error
+		return NewSupplierError(r.Main.ErrorResponse)
 	}
 
 	return nil
@@ -18,7 +18,7 @@ type BookingInfoForAmendmentRoot struct {
 
 func (r BookingInfoForAmendmentRoot) CheckError() error {
 	if r.Header.OperationType == OperationTypeError || r.Header.OperationType == OperationTypeMessage {
-		return r.Main.ErrorResponse.Error
+		return NewSupplierError(r.Main.ErrorResponse)
 	}
 
 	return nil
@@ -36,7 +36,7 @@ type BookingInfoForAmendmentMainResponse struct {
 
 type BookingInfoForAmendmentResponse struct {
 	//Check In Date	2013-10-08
-	ArrivalDate string `xml:"ArrivalDate"`
+	ArrivalDate Date `xml:"ArrivalDate"`
 	//Number of nights
 	Nights int64 `xml:"Nights"`
 	//Room List
@@ -19,7 +19,7 @@ type BookingInsertRequest struct {
 	//The Hotel search code of the chosen hotel
 	HotelSearchCode string `xml:"HotelSearchCode"`
 	//Check-in Date (yyyy-MM-dd)
-	ArrivalDate string `xml:"ArrivalDate"`
+	ArrivalDate Date `xml:"ArrivalDate"`
 	//Number of nights
 	Nights int64 `xml:"Nights"`
 	//Indicates whether client wants to be advised on alternates if his room is unavailable for booking (1=no alternatives)
@@ -108,14 +108,21 @@ type BookingInsertRoot struct {
 
 func (r BookingInsertRoot) CheckError() error {
 	if r.Header.OperationType == OperationTypeError || r.Header.OperationType == OperationTypeMessage {
-		return r.Main.ErrorResponse.Error
+		return NewSupplierError(r.Main.ErrorResponse)
 	}
 
 	return nil
 }
 
 func (r BookingInsertRoot) GetResponse() BookingInsertResponse {
-	return r.Main.BookingInsertResponse
+	response := r.Main.BookingInsertResponse
+	response.TotalPrice.withCurrency(response.Currency)
+	response.TotalTax.withCurrency(response.Currency)
+	response.RoomRate.withCurrency(response.Currency)
+	response.Commission.Value.withCurrency(response.Currency)
+	response.PaymentInfo.PaymentResult.Amount.withCurrency(response.PaymentInfo.PaymentResult.Currency)
+
+	return response
 }
 
 type BookInsertMainResponse struct {
@@ -134,13 +141,13 @@ type BookingInsertResponse struct {
 	//The status of the booking RQ, X, C etc.
 	BookingStatus string `xml:"BookingStatus"`
 	//The total booking price
-	TotalPrice float64 `xml:"TotalPrice"`
+	TotalPrice Money `xml:"TotalPrice"`
 	//Currency
 	Currency string `xml:"Currency"`
 	// Total Tax for booking
-	TotalTax float64 `xml:"TotalTax"`
+	TotalTax Money `xml:"TotalTax"`
 	// Total without tax
-	RoomRate float64 `xml:"RoomRate"`
+	RoomRate Money `xml:"RoomRate"`
 	//The Comm flat value - with IncludeCommission
 	Commission Commission `xml:"Commission"`
 	//Id of the Hotel - Version 2+ Only
@@ -154,9 +161,9 @@ type BookingInsertResponse struct {
 	//RoomBasis
 	RoomBasis string `xml:"RoomBasis"`
 	//Check-in Date (yyyy-MM-dd)
-	ArrivalDate string `xml:"ArrivalDate"`
+	ArrivalDate Date `xml:"ArrivalDate"`
 	//CXL deadline date
-	CancellationDeadline string `xml:"CancellationDeadline"`
+	CancellationDeadline Date `xml:"CancellationDeadline"`
 	//Number of nights
 	Nights int64 `xml:"Nights"`
 	//Don't return alternative
@@ -179,7 +186,7 @@ type Commission struct {
 	XMLName xml.Name `xml:"Commission"`
 	//Attribute - The Comm % value - with IncludeCommission
 	Pct   float64 `xml:"pct,attr"`
-	Value string  `xml:",chardata"`
+	Value Money   `xml:",chardata"`
 }
 
 type RoomsResponse struct {
@@ -215,7 +222,7 @@ type PaymentTransactions struct {
 type Transaction struct {
 	XMLName xml.Name `xml:"Transaction"`
 	//Attribute - Date of the transaction (format: 2022-11-01 00:00:00)
-	Date string `xml:"Date,attr"`
+	Date DateTime `xml:"Date,attr"`
 	//Attribute - Type of the transaction: Payment|Refund
 	Type string `xml:"Type,attr"`
 	//Attribute - Transaction Category: Reservation|Refund
@@ -223,11 +230,11 @@ type Transaction struct {
 	//Attribute - Form of payment: BANK_TRANSFER, CREDITCARD, etc.
 	Method string `xml:"Method,attr"`
 	//Attribute - Paid amount in local currency
-	PaidAmount float64 `xml:"PaidAmount,attr"`
+	PaidAmount Money `xml:"PaidAmount,attr"`
 	//Attribute - Currency of the paid amount - ISO Code
 	PaidCurrency string `xml:"PaidCurrency,attr"`
 	//Attribute - Paid amount in Booking Currency
-	BookingAmount float64 `xml:"BookingAmount"`
+	BookingAmount Money `xml:"BookingAmount"`
 	//Attribute - Currency of the booking - ISO Code
 	BookingCurrency string `xml:"BookingCurrency"`
 	//Attribute - Local to Booking exchange rate at time of transaction
@@ -247,7 +254,7 @@ type PaymentResult struct {
 	//Indication if the payment completed correctly
 	Successful bool `xml:"Successful"`
 	//Amount charged for the booking
-	Amount float64 `xml:"Amount"`
+	Amount Money `xml:"Amount"`
 	//Currency used to pay for the booking - booking currency
 	Currency string `xml:"Currency"`
 	//Confirmation code for the transaction
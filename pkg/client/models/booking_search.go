@@ -22,14 +22,26 @@ type BookingSearchRoot struct {
 
 func (r BookingSearchRoot) CheckError() error {
 	if r.Header.OperationType == OperationTypeError || r.Header.OperationType == OperationTypeMessage {
-		return r.Main.ErrorResponse.Error
+		return NewSupplierError(r.Main.ErrorResponse)
 	}
 
 	return nil
 }
 
 func (r BookingSearchRoot) GetResponse() BookingSearchResponse {
-	return r.Main.BookingSearchResponse
+	response := r.Main.BookingSearchResponse
+	response.TotalPrice.withCurrency(response.Currency)
+	response.GrossPrice.Value.withCurrency(response.GrossPrice.Currency)
+	response.Commission.Value.withCurrency(response.Currency)
+
+	transactions := append([]Transaction(nil), response.PaymentTransactions.Transaction...)
+	for i := range transactions {
+		transactions[i].PaidAmount.withCurrency(transactions[i].PaidCurrency)
+		transactions[i].BookingAmount.withCurrency(transactions[i].BookingCurrency)
+	}
+	response.PaymentTransactions.Transaction = transactions
+
+	return response
 }
 
 type BookingSearchMainResponse struct {
@@ -49,7 +61,7 @@ type BookingSearchResponse struct {
 	//The status of the booking RQ, X, C etc.
 	BookingStatus string `xml:"BookingStatus"`
 	//The total client price
-	TotalPrice float64 `xml:"TotalPrice"`
+	TotalPrice Money `xml:"TotalPrice"`
 	//Currency
 	Currency string `xml:"Currency"`
 	//The total agent price
@@ -69,7 +81,7 @@ type BookingSearchResponse struct {
 	//BoardBasis
 	RoomBasis string `xml:"RoomBasis,omitempty"`
 	//Check-in Date (yyyy-MM-dd)
-	ArrivalDate string `xml:"ArrivalDate,omitempty"`
+	ArrivalDate Date `xml:"ArrivalDate,omitempty"`
 	//Transfer Country
 	Country string `xml:"Country,omitempty"`
 	//Description of Transfer
@@ -79,9 +91,9 @@ type BookingSearchResponse struct {
 	//Location of Dropoff
 	DropOffLocation string `xml:"DropOffLocation,omitempty"`
 	//Pickup Date and time (yyyy-MM-dd HH:mm)
-	PickupDate string `xml:"PickupDate,omitempty"`
+	PickupDate DateTime `xml:"PickupDate,omitempty"`
 	//Cancellaton deadline date
-	CancellationDeadline string `xml:"CancellationDeadline"`
+	CancellationDeadline Date `xml:"CancellationDeadline"`
 	//Number of nights
 	Nights int64 `xml:"Nights,omitempty"`
 	//No Alternative Hotel Returned
@@ -105,7 +117,7 @@ type BookingSearchResponse struct {
 type GrossPrice struct {
 	XMLName  xml.Name `xml:"GrossPrice"`
 	Currency string   `xml:"Currency,attr"`
-	Value    string   `xml:",chardata"`
+	Value    Money    `xml:",chardata"`
 }
 
 type BookingSearchRoomsResponse struct {
@@ -18,14 +18,17 @@ type BookingStatusRoot struct {
 
 func (r BookingStatusRoot) CheckError() error {
 	if r.Header.OperationType == OperationTypeError || r.Header.OperationType == OperationTypeMessage {
-		return r.Main.ErrorResponse.Error
+		return NewSupplierError(r.Main.ErrorResponse)
 	}
 
 	return nil
 }
 
 func (r BookingStatusRoot) GetResponse() BookingStatusResponse {
-	return r.Main.BookingStatusResponse
+	response := r.Main.BookingStatusResponse
+	response.GoBookingCode.TotalPrice.withCurrency(response.GoBookingCode.Currency)
+
+	return response
 }
 
 type BookingStatusMainResponse struct {
@@ -46,7 +49,7 @@ type GoBookingCode struct {
 	//	The Go Reference
 	GoReference string `xml:"GoReference,attr"`
 	//	The total booking price
-	TotalPrice float64 `xml:"TotalPrice,attr"`
+	TotalPrice Money `xml:"TotalPrice,attr"`
 	//	Currency
 	Currency string `xml:"Currency,attr"`
 	//The Go booking code
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"strings"
 )
 
 const (
@@ -171,9 +172,9 @@ type Header struct {
 
 type SearchStats struct {
 	//Number of hotels returned
-	HotelQty int `json:"HotelQty"`
+	HotelQty int `json:"HotelQty" xml:"HotelQty"`
 	//Number of results returned
-	ResultsQty int `json:"ResultsQty"`
+	ResultsQty int `json:"ResultsQty" xml:"ResultsQty"`
 }
 
 type EnvelopeResponse struct {
@@ -187,6 +188,40 @@ type EnvelopeResponse struct {
 type BodyResponse struct {
 	XMLName             xml.Name `xml:"Body"`
 	MakeRequestResponse MakeRequestResponse
+	//Fill only if the endpoint failed to process the request
+	Fault *SoapFault `xml:"http://schemas.xmlsoap.org/soap/envelope/ Fault"`
+}
+
+// SoapFault is returned by the endpoint instead of MakeRequestResponse when the request can't be processed
+type SoapFault struct {
+	XMLName xml.Name `xml:"http://schemas.xmlsoap.org/soap/envelope/ Fault"`
+	//Fault code, eg. soap:Client or soap:Server
+	Code string `xml:"faultcode"`
+	//Human readable explanation
+	String string `xml:"faultstring"`
+	//Who caused the fault
+	Actor string `xml:"faultactor,omitempty"`
+	//Application specific details
+	Detail SoapFaultDetail `xml:"detail"`
+}
+
+type SoapFaultDetail struct {
+	//Raw content of the detail element
+	Content string `xml:",innerxml"`
+}
+
+func (f *SoapFault) Error() string {
+	msg := fmt.Sprintf("soap fault: %s: %s", f.Code, f.String)
+	if detail := strings.TrimSpace(f.Detail.Content); detail != "" {
+		msg = fmt.Sprintf("%s, detail: %s", msg, detail)
+	}
+
+	return msg
+}
+
+// IsClient reports whether the fault was caused by the request, so repeating it won't help
+func (f *SoapFault) IsClient() bool {
+	return strings.HasSuffix(f.Code, "Client")
 }
 
 type MakeRequestResponse struct {
@@ -0,0 +1,317 @@
+package models
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	//DateFormat is sent in requests (yyyy-MM-dd)
+	DateFormat = "2006-01-02"
+	//DateTimeFormat is sent in requests (yyyy-MM-dd HH:mm)
+	DateTimeFormat = "2006-01-02 15:04"
+)
+
+// dateTimeLayouts are date and time formats found in the supplier responses
+var dateTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+	"02/Jan/06 15:04",
+	"02/Jan/2006 15:04",
+}
+
+// dateLayouts are date formats found in the supplier responses
+var dateLayouts = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"02/Jan/06",
+	"02/Jan/2006",
+	"02-Jan-06",
+	"02-Jan-2006",
+	"02 Jan 2006",
+	"20060102",
+}
+
+// emptyDates are placeholders the supplier uses for missing dates
+var emptyDates = map[string]bool{
+	"":           true,
+	"0000-00-00": true,
+	"00/00/0000": true,
+}
+
+// Date is a calendar date. It's parsed from any of the supplier formats (yyyy-MM-dd, dd/MM/yyyy, dd/MMM/yy, etc.)
+// and sent as yyyy-MM-dd. The zero Date is parsed from empty values and omitted from requests.
+// Values in unknown formats don't fail the decoding of the response: the Date stays zero, keeps the text
+// (see Raw) and reports the problem with Valid and Err
+type Date struct {
+	t time.Time
+	//supplier text that could not be parsed
+	raw string
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{t: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateOf returns the date of t in its location
+func DateOf(t time.Time) Date {
+	if t.IsZero() {
+		return Date{}
+	}
+
+	return NewDate(t.Date())
+}
+
+// ParseDate parses date in any of the supplier formats, date and time values are truncated to the date
+func ParseDate(value string) (Date, error) {
+	value = strings.TrimSpace(value)
+	if emptyDates[value] {
+		return Date{}, nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return DateOf(t), nil
+		}
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return DateOf(t), nil
+		}
+	}
+
+	return Date{}, fmt.Errorf("unsupported date format %q", value)
+}
+
+// Time returns midnight UTC of the date, the zero time for the zero Date
+func (d Date) Time() time.Time {
+	return d.t
+}
+
+// IsZero reports whether there is no date, either missing or not parsed
+func (d Date) IsZero() bool {
+	return d.t.IsZero()
+}
+
+// Valid reports whether the date was parsed or is missing, false for values in unknown formats
+func (d Date) Valid() bool {
+	return d.raw == ""
+}
+
+// Err returns the parse error of a value in unknown format, nil for valid dates
+func (d Date) Err() error {
+	if d.Valid() {
+		return nil
+	}
+
+	return fmt.Errorf("unsupported date format %q", d.raw)
+}
+
+// Raw returns the supplier text of a value in unknown format, String for valid dates
+func (d Date) Raw() string {
+	if d.Valid() {
+		return d.String()
+	}
+
+	return d.raw
+}
+
+// AddDays returns the date days later, e.g. the check-out date of ArrivalDate and Nights
+func (d Date) AddDays(days int) Date {
+	return Date{t: d.t.AddDate(0, 0, days)}
+}
+
+func (d Date) Before(other Date) bool {
+	return d.t.Before(other.t)
+}
+
+func (d Date) After(other Date) bool {
+	return d.t.After(other.t)
+}
+
+// String returns yyyy-MM-dd, empty string for the zero Date
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+
+	return d.t.Format(DateFormat)
+}
+
+// MarshalText returns yyyy-MM-dd, values in unknown formats are returned as they were received
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.Raw()), nil
+}
+
+// UnmarshalText never fails, values in unknown formats are kept as they are, see Valid
+func (d *Date) UnmarshalText(text []byte) error {
+	parsed, err := ParseDate(string(text))
+	if err != nil {
+		parsed = Date{raw: string(text)}
+	}
+	*d = parsed
+
+	return nil
+}
+
+// MarshalXML omits the zero Date, as omitempty does not apply to structs
+func (d Date) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if d.Raw() == "" {
+		return nil
+	}
+
+	return e.EncodeElement(d.Raw(), start)
+}
+
+// MarshalXMLAttr omits the zero Date
+func (d Date) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	if d.Raw() == "" {
+		return xml.Attr{}, nil
+	}
+
+	return xml.Attr{Name: name, Value: d.Raw()}, nil
+}
+
+// DateTime is a date and time without time zone, the supplier sends local time of the hotel or the agency.
+// It's parsed from any of the supplier formats (yyyy-MM-dd HH:mm, yyyy-MM-dd HH:mm:ss, etc.), values without time
+// are parsed as midnight. It's sent as yyyy-MM-dd HH:mm. The zero DateTime is omitted from requests.
+// As with Date, values in unknown formats are kept as they are and reported by Valid and Err
+type DateTime struct {
+	t time.Time
+	//supplier text that could not be parsed
+	raw string
+}
+
+// DateTimeOf returns t with its location dropped
+func DateTimeOf(t time.Time) DateTime {
+	if t.IsZero() {
+		return DateTime{}
+	}
+
+	return DateTime{t: time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)}
+}
+
+// ParseDateTime parses date and time in any of the supplier formats
+func ParseDateTime(value string) (DateTime, error) {
+	value = strings.TrimSpace(value)
+	if emptyDates[value] {
+		return DateTime{}, nil
+	}
+
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return DateTimeOf(t), nil
+		}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return DateTimeOf(t), nil
+		}
+	}
+
+	return DateTime{}, fmt.Errorf("unsupported date time format %q", value)
+}
+
+// Time returns the date and time in UTC, use In to put them in the right location
+func (d DateTime) Time() time.Time {
+	return d.t
+}
+
+// In returns the same wall clock time in loc
+func (d DateTime) In(loc *time.Location) time.Time {
+	if d.IsZero() {
+		return time.Time{}
+	}
+
+	return time.Date(d.t.Year(), d.t.Month(), d.t.Day(), d.t.Hour(), d.t.Minute(), d.t.Second(), d.t.Nanosecond(), loc)
+}
+
+func (d DateTime) Date() Date {
+	return DateOf(d.t)
+}
+
+// IsZero reports whether there is no date and time, either missing or not parsed
+func (d DateTime) IsZero() bool {
+	return d.t.IsZero()
+}
+
+// Valid reports whether the value was parsed or is missing, false for values in unknown formats
+func (d DateTime) Valid() bool {
+	return d.raw == ""
+}
+
+// Err returns the parse error of a value in unknown format, nil for valid values
+func (d DateTime) Err() error {
+	if d.Valid() {
+		return nil
+	}
+
+	return fmt.Errorf("unsupported date time format %q", d.raw)
+}
+
+// Raw returns the supplier text of a value in unknown format, String for valid values
+func (d DateTime) Raw() string {
+	if d.Valid() {
+		return d.String()
+	}
+
+	return d.raw
+}
+
+func (d DateTime) Before(other DateTime) bool {
+	return d.t.Before(other.t)
+}
+
+func (d DateTime) After(other DateTime) bool {
+	return d.t.After(other.t)
+}
+
+// String returns yyyy-MM-dd HH:mm, empty string for the zero DateTime
+func (d DateTime) String() string {
+	if d.IsZero() {
+		return ""
+	}
+
+	return d.t.Format(DateTimeFormat)
+}
+
+// MarshalText returns yyyy-MM-dd HH:mm, values in unknown formats are returned as they were received
+func (d DateTime) MarshalText() ([]byte, error) {
+	return []byte(d.Raw()), nil
+}
+
+// UnmarshalText never fails, values in unknown formats are kept as they are, see Valid
+func (d *DateTime) UnmarshalText(text []byte) error {
+	parsed, err := ParseDateTime(string(text))
+	if err != nil {
+		parsed = DateTime{raw: string(text)}
+	}
+	*d = parsed
+
+	return nil
+}
+
+// MarshalXML omits the zero DateTime, as omitempty does not apply to structs
+func (d DateTime) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if d.Raw() == "" {
+		return nil
+	}
+
+	return e.EncodeElement(d.Raw(), start)
+}
+
+// MarshalXMLAttr omits the zero DateTime
+func (d DateTime) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	if d.Raw() == "" {
+		return xml.Attr{}, nil
+	}
+
+	return xml.Attr{Name: name, Value: d.Raw()}, nil
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Date
+	}{
+		{"2011-03-03", NewDate(2011, time.March, 3)},
+		{"03/03/2011", NewDate(2011, time.March, 3)},
+		{"25/12/2023", NewDate(2023, time.December, 25)},
+		{"03/Mar/11", NewDate(2011, time.March, 3)},
+		{"03/Mar/2011", NewDate(2011, time.March, 3)},
+		{"2011-03-03 14:30", NewDate(2011, time.March, 3)},
+		{" 2011-03-03 ", NewDate(2011, time.March, 3)},
+		{"", Date{}},
+		{"0000-00-00", Date{}},
+	}
+
+	for _, test := range tests {
+		got, err := ParseDate(test.value)
+		if err != nil {
+			t.Errorf("ParseDate(%q): %v", test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseDate(%q) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2011-03-03 14:30", time.Date(2011, time.March, 3, 14, 30, 0, 0, time.UTC)},
+		{"2011-03-03 14:30:15", time.Date(2011, time.March, 3, 14, 30, 15, 0, time.UTC)},
+		{"2011-03-03T14:30:15", time.Date(2011, time.March, 3, 14, 30, 15, 0, time.UTC)},
+		{"03/03/2011 14:30", time.Date(2011, time.March, 3, 14, 30, 0, 0, time.UTC)},
+		{"03/Mar/11 14:30", time.Date(2011, time.March, 3, 14, 30, 0, 0, time.UTC)},
+		{"2011-03-03", time.Date(2011, time.March, 3, 0, 0, 0, 0, time.UTC)},
+		{"03/Mar/11", time.Date(2011, time.March, 3, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		got, err := ParseDateTime(test.value)
+		if err != nil {
+			t.Errorf("ParseDateTime(%q): %v", test.value, err)
+			continue
+		}
+		if !got.Time().Equal(test.want) {
+			t.Errorf("ParseDateTime(%q) = %s, want %s", test.value, got.Time(), test.want)
+		}
+	}
+}
+
+func TestDateUnknownFormat(t *testing.T) {
+	var d Date
+	if err := d.UnmarshalText([]byte("next Monday")); err != nil {
+		t.Fatalf("UnmarshalText failed: %v", err)
+	}
+	if d.Valid() || d.Err() == nil || !d.IsZero() || d.Raw() != "next Monday" {
+		t.Errorf("got valid %v, err %v, zero %v, raw %q", d.Valid(), d.Err(), d.IsZero(), d.Raw())
+	}
+
+	valid := NewDate(2011, time.March, 3)
+	if !valid.Valid() || valid.Err() != nil || valid.Raw() != "2011-03-03" {
+		t.Errorf("got valid %v, err %v, raw %q", valid.Valid(), valid.Err(), valid.Raw())
+	}
+
+	var dt DateTime
+	if err := dt.UnmarshalText([]byte("soon")); err != nil || dt.Valid() || dt.Err() == nil || dt.Raw() != "soon" {
+		t.Errorf("got error %v, valid %v, raw %q", err, dt.Valid(), dt.Raw())
+	}
+}
+
+func TestUnknownDateFormatDoesNotFailDecoding(t *testing.T) {
+	response := `<Root><Header><OperationType>Response</OperationType></Header><Main>` +
+		`<GoBookingCode>123</GoBookingCode><ArrivalDate>sometime in March</ArrivalDate>` +
+		`<CancellationDeadline>2011-03-01</CancellationDeadline></Main></Root>`
+
+	root := BookingInsertRoot{}
+	if err := xml.Unmarshal([]byte(response), &root); err != nil {
+		t.Fatalf("decoding failed: %v", err)
+	}
+	booking := root.GetResponse()
+	if booking.ArrivalDate.Valid() || booking.ArrivalDate.Raw() != "sometime in March" {
+		t.Errorf("got arrival date %q, valid %v", booking.ArrivalDate.Raw(), booking.ArrivalDate.Valid())
+	}
+	if booking.CancellationDeadline != NewDate(2011, time.March, 1) {
+		t.Errorf("got cancellation deadline %s", booking.CancellationDeadline)
+	}
+
+	offer := HotelSearchOffer{}
+	if err := json.Unmarshal([]byte(`{"CxlDeadline":"TBA","TotalPrice":10}`), &offer); err != nil {
+		t.Fatalf("decoding failed: %v", err)
+	}
+	if offer.CxlDeadline.Valid() || offer.CxlDeadline.Raw() != "TBA" {
+		t.Errorf("got cancellation deadline %q", offer.CxlDeadline.Raw())
+	}
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrAuthentication         = errors.New("authentication failed")
+	ErrNoAvailability         = errors.New("no availability")
+	ErrPriceChanged           = errors.New("price changed")
+	ErrSearchCodeExpired      = errors.New("search code expired")
+	ErrBookingNotFound        = errors.New("booking not found")
+	ErrCancellationNotAllowed = errors.New("cancellation not allowed")
+	ErrTimeout                = errors.New("supplier timeout")
+	ErrServiceUnavailable     = errors.New("supplier service unavailable")
+)
+
+// errorMessageKinds maps supplier messages to the sentinel errors above. The supplier does not document
+// its error codes, so errors are classified by the English message only and the code is ignored.
+// Patterns are phrases of whole supplier messages, not single words, and outages are checked first
+// so "Service not available" is not taken for a sold out hotel
+var errorMessageKinds = []struct {
+	kind     error
+	patterns []string
+}{
+	{ErrServiceUnavailable, []string{"service not available", "service unavailable", "service is not available", "server is busy", "temporarily unavailable", "under maintenance"}},
+	{ErrTimeout, []string{"timeout expired", "request timeout", "timed out"}},
+	{ErrAuthentication, []string{"authentication failed", "invalid user", "invalid password", "wrong password", "login failed", "access denied"}},
+	{ErrSearchCodeExpired, []string{"search code expired", "searchcode expired", "hotelsearchcode expired", "invalid hotelsearchcode", "invalid hotel search code"}},
+	{ErrPriceChanged, []string{"price changed", "price has changed"}},
+	{ErrCancellationNotAllowed, []string{"booking cannot be cancelled", "booking can not be cancelled", "cancellation not allowed", "cancellation is not allowed"}},
+	{ErrBookingNotFound, []string{"booking not found", "booking does not exist", "reservation not found"}},
+	{ErrNoAvailability, []string{"no availability", "hotel is not available", "room is not available", "rooms are not available", "sold out", "no hotels found"}},
+}
+
+// SupplierError is a business error returned by Go Global with details of DebugError.
+// It matches the sentinel of its kind with errors.Is and GoGlobalError with errors.As
+type SupplierError struct {
+	GoGlobalError
+	//Incident id of DebugError, useful in supplier support requests
+	Incident int64
+	//Timestamp of DebugError
+	TimeStamp string
+	//Debug message
+	DebugMessage string
+	//Action taken by the supplier
+	FinalAction string
+	//One of Err* sentinels, nil when the error is not classified
+	Kind error
+}
+
+func NewSupplierError(response ErrorResponse) *SupplierError {
+	return &SupplierError{
+		GoGlobalError: response.Error,
+		Incident:      response.DebugError.Incident,
+		TimeStamp:     response.DebugError.TimeStamp,
+		DebugMessage:  response.DebugError.Message,
+		FinalAction:   response.DebugError.FinalAction,
+		Kind:          classifyError(response.Error),
+	}
+}
+
+func (e *SupplierError) Error() string {
+	msg := e.GoGlobalError.Error()
+	if e.Incident != 0 {
+		msg = fmt.Sprintf("%s, incident: %d", msg, e.Incident)
+	}
+	if e.TimeStamp != "" {
+		msg = fmt.Sprintf("%s, timestamp: %s", msg, e.TimeStamp)
+	}
+
+	return msg
+}
+
+func (e *SupplierError) Unwrap() error {
+	return e.Kind
+}
+
+// As allows errors.As(err, &GoGlobalError{}) for callers matching the flat supplier error
+func (e *SupplierError) As(target any) bool {
+	if t, ok := target.(*GoGlobalError); ok {
+		*t = e.GoGlobalError
+		return true
+	}
+
+	return false
+}
+
+// classifyError returns the sentinel matching the message of e, nil when no pattern matches
+func classifyError(e GoGlobalError) error {
+	message := strings.ToLower(e.Message)
+	for _, k := range errorMessageKinds {
+		for _, pattern := range k.patterns {
+			if strings.Contains(message, pattern) {
+				return k.kind
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		message string
+		kind    error
+	}{
+		{"Service not available", ErrServiceUnavailable},
+		{"The service is not available, please try later", ErrServiceUnavailable},
+		{"Hotel is not available", ErrNoAvailability},
+		{"No availability for the requested dates", ErrNoAvailability},
+		{"Login failed", ErrAuthentication},
+		{"Price has changed", ErrPriceChanged},
+		{"Booking not found", ErrBookingNotFound},
+		{"Request timed out", ErrTimeout},
+		{"Unexpected error", nil},
+	}
+
+	for _, test := range tests {
+		err := NewSupplierError(ErrorResponse{Error: GoGlobalError{Code: 1, Message: test.message}})
+		if err.Kind != test.kind {
+			t.Errorf("%q: got kind %v, want %v", test.message, err.Kind, test.kind)
+		}
+		if test.kind != nil && !errors.Is(err, test.kind) {
+			t.Errorf("%q: errors.Is(%v) is false", test.message, test.kind)
+		}
+	}
+}
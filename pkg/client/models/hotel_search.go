@@ -1,6 +1,9 @@
 package models
 
-import "encoding/xml"
+import (
+	"encoding/json"
+	"encoding/xml"
+)
 
 type HotelSearchRequest struct {
 	XMLName xml.Name `xml:"Main"`
@@ -43,7 +46,7 @@ type HotelSearchRequest struct {
 	//Group to search for hotel by ID
 	Hotels SearchHotels `xml:"Hotels,omitempty"`
 	//Check-in Date (yyyy-MM-dd)
-	ArrivalDate string `xml:"ArrivalDate"`
+	ArrivalDate Date `xml:"ArrivalDate"`
 	//Number of nights
 	Nights int64 `xml:"Nights"`
 	//Single Stars Code/ID to filter results - from the list here Or Group for Stars Range Filter
@@ -64,6 +67,28 @@ type SearchHotels struct {
 	HotelId []int64 `xml:"HotelId"`
 }
 
+// MaxSearchHotelIds max number of SearchHotels.HotelId accepted by the supplier in one request
+const MaxSearchHotelIds = 500
+
+// SplitHotelIds splits ids into groups of up to size ids (MaxSearchHotelIds when size <= 0)
+func SplitHotelIds(ids []int64, size int) []SearchHotels {
+	if size <= 0 || size > MaxSearchHotelIds {
+		size = MaxSearchHotelIds
+	}
+
+	batches := make([]SearchHotels, 0, (len(ids)+size-1)/size)
+	for len(ids) > 0 {
+		n := size
+		if n > len(ids) {
+			n = len(ids)
+		}
+		batches = append(batches, SearchHotels{HotelId: ids[:n:n]})
+		ids = ids[n:]
+	}
+
+	return batches
+}
+
 type SearchStars struct {
 	//Attribute for the minimum Stars Code/Id to filter
 	MinStar string `xml:"MinStar,attr,omitempty"`
@@ -99,75 +124,104 @@ type HotelSearchResponse struct {
 
 type HotelSearchResponseItem struct {
 	//Name of Hotel
-	HotelName string `json:"HotelName"`
+	HotelName string `json:"HotelName" xml:"HotelName"`
 	//Unique HotelID code for the hote
-	HotelCode int `json:"HotelCode"`
+	HotelCode int `json:"HotelCode" xml:"HotelCode"`
 	//Country Id of hotel
-	CountryId int `json:"CountryId"`
+	CountryId int `json:"CountryId" xml:"CountryId"`
 	//City Id of hotel
-	CityId int `json:"CityId"`
+	CityId int `json:"CityId" xml:"CityId"`
 	//Text Location of the Hotel - City Centre, Airport, etc.
-	Location string `json:"Location"`
+	Location string `json:"Location" xml:"Location"`
 	//Location Code
-	LocationCode string `json:"LocationCode"`
+	LocationCode string `json:"LocationCode" xml:"LocationCode"`
 	//Thumbnail images of hotel
-	Thumbnail string `json:"Thumbnail"`
+	Thumbnail string `json:"Thumbnail" xml:"Thumbnail"`
 	//Longitude
-	Longitude float64 `json:"Longitude"`
+	Longitude float64 `json:"Longitude" xml:"Longitude"`
 	//Latitude
-	Latitude float64 `json:"Latitude"`
+	Latitude float64 `json:"Latitude" xml:"Latitude"`
 	//Hotel Rank in Destination
-	BestSellerRank string `json:"BestSellerRank"`
+	BestSellerRank string `json:"BestSellerRank" xml:"BestSellerRank"`
 	//Large High Quality Thumbnail
-	HotelImage string `json:"HotelImage"`
+	HotelImage string `json:"HotelImage" xml:"HotelImage"`
 	//array of HotelFacilities
-	HotelFacilities []string `json:"HotelFacilities"`
+	HotelFacilities []string `json:"HotelFacilities" xml:"HotelFacilities>Facility"`
 	//array of RoomFacilities
-	RoomFacilities []string           `json:"RoomFacilities"`
-	Offers         []HotelSearchOffer `json:"Offers"`
+	RoomFacilities []string           `json:"RoomFacilities" xml:"RoomFacilities>Facility"`
+	Offers         []HotelSearchOffer `json:"Offers" xml:"Offers>Offer"`
 }
 
 type HotelSearchOffer struct {
 	//Unique Code session code - used for subsequent requests
-	HotelSearchCode string `json:"HotelSearchCode"`
+	HotelSearchCode string `json:"HotelSearchCode" xml:"HotelSearchCode"`
 	//Cancellation Deadline
-	CxlDeadline string `json:"CxlDeadline"`
+	CxlDeadline Date `json:"CxlDeadline" xml:"CxlDeadline"`
 	//Indication of Refundability
-	NonRef bool `json:"NonRef"`
+	NonRef bool `json:"NonRef" xml:"NonRef"`
 	//array of roomNames
-	Rooms []string `json:"Rooms"`
+	Rooms []string `json:"Rooms" xml:"Rooms>Room"`
 	//BoardBasis - BB, RO
-	RoomBasis string `json:"RoomBasis"`
+	RoomBasis string `json:"RoomBasis" xml:"RoomBasis"`
 	//1- Hotel is Available , 0 - NotAvailable
-	Availability int `json:"Availability"`
+	Availability int `json:"Availability" xml:"Availability"`
 	//Total Price
-	TotalPrice float64 `json:"TotalPrice"`
+	TotalPrice Money `json:"TotalPrice" xml:"TotalPrice"`
 	//ISO Currency code
-	Currency string `json:"Currency"`
+	Currency string `json:"Currency" xml:"Currency"`
 	// Total Tax for booking
-	TotalTax float64 `json:"TotalTax"`
+	TotalTax Money `json:"TotalTax" xml:"TotalTax"`
 	// Total without tax
-	RoomRate float64 `json:"RoomRate"`
-	//The Comm flat value
-	CommPercent *float64 `json:"CommPercent"`
+	RoomRate Money `json:"RoomRate" xml:"RoomRate"`
 	//The Comm % value
-	CommValue *float64 `json:"CommValue"`
+	CommPercent *float64 `json:"CommPercent" xml:"CommPercent"`
+	//The Comm flat value
+	CommValue Money `json:"CommValue" xml:"CommValue"`
 	//Star Rating of the Hotel
-	Category string `json:"Category"`
+	Category string `json:"Category" xml:"Category"`
 	//Free text remark
-	Remark string `json:"Remark"`
+	Remark string `json:"Remark" xml:"Remark"`
 	//Special remarks
-	Special string `json:"Special"`
+	Special string `json:"Special" xml:"Special"`
 	//Is Best buy
-	Preferred            bool                 `json:"Preferred"`
-	CancellationPolicies []CancellationPolicy `json:"CancellationPolicies"`
+	Preferred            bool                 `json:"Preferred" xml:"Preferred"`
+	CancellationPolicies []CancellationPolicy `json:"CancellationPolicies" xml:"CancellationPolicies>Policy"`
+}
+
+// hotelSearchOffer has the fields of HotelSearchOffer without its unmarshal methods
+type hotelSearchOffer HotelSearchOffer
+
+func (o *HotelSearchOffer) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*hotelSearchOffer)(o)); err != nil {
+		return err
+	}
+	o.setCurrency()
+
+	return nil
+}
+
+func (o *HotelSearchOffer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if err := d.DecodeElement((*hotelSearchOffer)(o), &start); err != nil {
+		return err
+	}
+	o.setCurrency()
+
+	return nil
+}
+
+// setCurrency sets Currency of the offer to its prices
+func (o *HotelSearchOffer) setCurrency() {
+	o.TotalPrice.withCurrency(o.Currency)
+	o.TotalTax.withCurrency(o.Currency)
+	o.RoomRate.withCurrency(o.Currency)
+	o.CommValue.withCurrency(o.Currency)
 }
 
 type CancellationPolicy struct {
 	//Policy Index starting at 1
 	Id int64 `json:"Id" xml:"Id,attr"`
 	//Date when policy takes affect	(dd/mm/yyyy)
-	Starting string `json:"Starting" xml:"Starting,attr"`
+	Starting Date `json:"Starting" xml:"Starting,attr"`
 	//How to Apply the penalty
 	BasedOn string `json:"BasedOn" xml:"BasedOn,attr"`
 	//Is value %(PCT|FLAT)
@@ -176,4 +230,41 @@ type CancellationPolicy struct {
 	Value string `json:"Value" xml:",chardata"`
 }
 
+type HotelSearchRoot struct {
+	XMLName xml.Name                `xml:"Root"`
+	Header  HotelSearchHeader       `xml:"Header"`
+	Main    HotelSearchMainResponse `xml:"Main"`
+}
+
+// HotelSearchHeader is Header of XML search response, there Stats is a child element of the header
+type HotelSearchHeader struct {
+	Header
+	Stats SearchStats `xml:"Stats"`
+}
+
+func (r HotelSearchRoot) CheckError() error {
+	if r.Header.OperationType == OperationTypeError || r.Header.OperationType == OperationTypeMessage {
+		return NewSupplierError(r.Main.ErrorResponse)
+	}
+
+	return nil
+}
+
+func (r HotelSearchRoot) GetResponse() HotelSearchResponse {
+	header := r.Header.Header
+	header.Stats = r.Header.Stats
+
+	return HotelSearchResponse{
+		Header: header,
+		Hotels: r.Main.Hotels,
+	}
+}
+
+type HotelSearchMainResponse struct {
+	XMLName xml.Name                  `xml:"Main"`
+	Hotels  []HotelSearchResponseItem `xml:"Hotel"`
+
+	ErrorResponse
+}
+
 //endregion
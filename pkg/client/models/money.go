@@ -0,0 +1,421 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxMoneyScale max number of decimal places kept by Money
+const maxMoneyScale = 18
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrMoneyOverflow    = errors.New("money overflow")
+)
+
+// Money is an exact decimal amount in ISO currency. Amounts are parsed from the supplier text as they are,
+// without going through float64. Arithmetic and comparison fail with ErrCurrencyMismatch on different currencies,
+// the zero Money (no amount, no currency) is compatible with any currency, so it can start a sum.
+// As with Date, amounts that can't be parsed don't fail the decoding of the response: the Money stays zero,
+// keeps the text (see Raw) and reports the problem with Valid and Err. Arithmetic with such Money fails
+type Money struct {
+	//amount is units * 10^-scale
+	units    int64
+	scale    int32
+	currency string
+	//supplier text that could not be parsed
+	raw string
+}
+
+// NewMoney returns amount in minor units with scale decimal places, e.g. NewMoney(1050, 2, "EUR") is 10.50 EUR
+func NewMoney(units int64, scale int32, currency string) Money {
+	if scale < 0 {
+		scale = 0
+	}
+	if scale > maxMoneyScale {
+		scale = maxMoneyScale
+	}
+
+	return Money{units: units, scale: scale, currency: normalizeCurrency(currency)}
+}
+
+// ParseMoney parses decimal amount, e.g. "1234.50", "-0,5", "1,234.50", "1.5e3". A single comma followed
+// by three digits, e.g. "1,234", may be either separator, so it's rejected instead of guessed
+func ParseMoney(amount string, currency string) (Money, error) {
+	m, err := parseAmount(amount)
+	if err != nil {
+		return Money{}, err
+	}
+	m.currency = normalizeCurrency(currency)
+
+	return m, nil
+}
+
+func parseAmount(amount string) (Money, error) {
+	value := strings.TrimSpace(amount)
+	if value == "" {
+		return Money{}, nil
+	}
+
+	exponent := int64(0)
+	if e := strings.IndexAny(value, "eE"); e >= 0 {
+		var err error
+		if exponent, err = strconv.ParseInt(value[e+1:], 10, 32); err != nil {
+			return Money{}, fmt.Errorf("invalid amount %q", amount)
+		}
+		value = value[:e]
+	}
+
+	switch groups := strings.Split(value, ","); {
+	case len(groups) == 1:
+	case strings.Contains(value, "."), len(groups) > 2:
+		//comma is a thousands separator then
+		value = strings.Join(groups, "")
+	case isThousandsGroup(groups[0], groups[1]):
+		return Money{}, fmt.Errorf("invalid amount %q: ambiguous decimal or thousands separator", amount)
+	default:
+		value = groups[0] + "." + groups[1]
+	}
+
+	negative := false
+	switch {
+	case strings.HasPrefix(value, "-"):
+		negative = true
+		value = value[1:]
+	case strings.HasPrefix(value, "+"):
+		value = value[1:]
+	}
+
+	integer, fraction := value, ""
+	if dot := strings.IndexByte(value, '.'); dot >= 0 {
+		integer, fraction = value[:dot], value[dot+1:]
+	}
+	if integer == "" && fraction == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", amount)
+	}
+	if len(fraction) > maxMoneyScale {
+		fraction = strings.TrimRight(fraction, "0")
+		if len(fraction) > maxMoneyScale {
+			return Money{}, fmt.Errorf("invalid amount %q: more than %d decimal places", amount, maxMoneyScale)
+		}
+	}
+
+	units := int64(0)
+	for _, c := range integer + fraction {
+		if c < '0' || c > '9' {
+			return Money{}, fmt.Errorf("invalid amount %q", amount)
+		}
+		if units > (math.MaxInt64-int64(c-'0'))/10 {
+			return Money{}, fmt.Errorf("invalid amount %q: %w", amount, ErrMoneyOverflow)
+		}
+		units = units*10 + int64(c-'0')
+	}
+	if negative {
+		units = -units
+	}
+
+	//exponent moves the decimal point, amounts with more places than the scale are multiplied
+	scale := int64(len(fraction)) - exponent
+	switch {
+	case scale > maxMoneyScale:
+		return Money{}, fmt.Errorf("invalid amount %q: more than %d decimal places", amount, maxMoneyScale)
+	case scale < -maxMoneyScale:
+		return Money{}, fmt.Errorf("invalid amount %q: %w", amount, ErrMoneyOverflow)
+	case scale < 0:
+		m, err := rescale(Money{units: units}, int32(-scale))
+		if err != nil {
+			return Money{}, fmt.Errorf("invalid amount %q: %w", amount, err)
+		}
+		return Money{units: m.units}, nil
+	}
+
+	return Money{units: units, scale: int32(scale)}, nil
+}
+
+// isThousandsGroup reports whether a single comma may be a thousands separator, e.g. in "1,234".
+// Leading zero or more than three digits before it make it a decimal comma
+func isThousandsGroup(integer, fraction string) bool {
+	integer = strings.TrimLeft(integer, "+-")
+	return len(fraction) == 3 && len(integer) > 0 && len(integer) <= 3 && integer[0] != '0'
+}
+
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// Valid reports whether the amount was parsed or is missing, false for amounts that could not be parsed
+func (m Money) Valid() bool {
+	return m.raw == ""
+}
+
+// Err returns the parse error of an amount that could not be parsed, nil for valid amounts
+func (m Money) Err() error {
+	if m.Valid() {
+		return nil
+	}
+	_, err := parseAmount(m.raw)
+
+	return err
+}
+
+// Raw returns the supplier text of an amount that could not be parsed, Amount for valid amounts
+func (m Money) Raw() string {
+	if m.Valid() {
+		return m.Amount()
+	}
+
+	return m.raw
+}
+
+// WithCurrency returns the same amount in currency
+func (m Money) WithCurrency(currency string) Money {
+	m.currency = normalizeCurrency(currency)
+	return m
+}
+
+// IsZero reports whether the amount is zero, missing or not parsed
+func (m Money) IsZero() bool {
+	return m.units == 0
+}
+
+// Sign returns -1, 0 or 1
+func (m Money) Sign() int {
+	switch {
+	case m.units < 0:
+		return -1
+	case m.units > 0:
+		return 1
+	}
+
+	return 0
+}
+
+func (m Money) Neg() Money {
+	m.units = -m.units
+	return m
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	a, b, err := align(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	if (b.units > 0 && a.units > math.MaxInt64-b.units) || (b.units < 0 && a.units < math.MinInt64-b.units) {
+		return Money{}, ErrMoneyOverflow
+	}
+	a.units += b.units
+
+	return a, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	return m.Add(other.Neg())
+}
+
+// Mul returns the amount multiplied by n, e.g. price per night by nights
+func (m Money) Mul(n int64) (Money, error) {
+	if err := m.Err(); err != nil {
+		return Money{}, err
+	}
+	if m.units != 0 && n != 0 {
+		res := m.units * n
+		if res/n != m.units || (m.units == -1 && n == math.MinInt64) || (n == -1 && m.units == math.MinInt64) {
+			return Money{}, ErrMoneyOverflow
+		}
+		m.units = res
+		return m, nil
+	}
+	m.units = 0
+
+	return m, nil
+}
+
+// Round rounds the amount half away from zero to places decimal places
+func (m Money) Round(places int32) Money {
+	if places < 0 {
+		places = 0
+	}
+	if m.scale <= places {
+		return m
+	}
+
+	div := pow10(m.scale - places)
+	q, r := m.units/div, m.units%div
+	if r*2 >= div {
+		q++
+	} else if r*2 <= -div {
+		q--
+	}
+
+	return Money{units: q, scale: places, currency: m.currency}
+}
+
+// Cmp returns -1, 0 or 1 when m is less than, equal to or greater than other
+func (m Money) Cmp(other Money) (int, error) {
+	diff, err := m.Sub(other)
+	if err != nil {
+		return 0, err
+	}
+
+	return diff.Sign(), nil
+}
+
+// Equal reports whether amounts and currencies are the same, 10.5 EUR equals 10.50 EUR
+func (m Money) Equal(other Money) bool {
+	cmp, err := m.Cmp(other)
+	return err == nil && cmp == 0 && m.currency == other.currency
+}
+
+// Amount returns the decimal amount, e.g. "10.50"
+func (m Money) Amount() string {
+	if m.scale == 0 {
+		return strconv.FormatInt(m.units, 10)
+	}
+
+	digits := strconv.FormatInt(m.units, 10)
+	sign := ""
+	if m.units < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if pad := int(m.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	dot := len(digits) - int(m.scale)
+
+	return sign + digits[:dot] + "." + digits[dot:]
+}
+
+// Float64 returns the nearest float64, for display only
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.Amount(), 64)
+	return f
+}
+
+// String returns the amount with currency, e.g. "10.50 EUR"
+func (m Money) String() string {
+	if m.currency == "" {
+		return m.Raw()
+	}
+
+	return m.Raw() + " " + m.currency
+}
+
+// MarshalText returns the amount only, the currency is a separate field in the supplier models.
+// Amounts that could not be parsed are returned as they were received
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(m.Raw()), nil
+}
+
+// UnmarshalText sets the amount, keeping the currency. It never fails, amounts that can't be parsed
+// are kept as they are, see Valid
+func (m *Money) UnmarshalText(text []byte) error {
+	parsed, err := parseAmount(string(text))
+	if err != nil {
+		parsed = Money{raw: string(text)}
+	}
+	parsed.currency = m.currency
+	*m = parsed
+
+	return nil
+}
+
+// MarshalJSON returns the amount as a JSON number, amounts that could not be parsed as a JSON string
+func (m Money) MarshalJSON() ([]byte, error) {
+	if !m.Valid() {
+		return []byte(strconv.Quote(m.raw)), nil
+	}
+
+	return []byte(m.Amount()), nil
+}
+
+// UnmarshalJSON accepts the amount as a JSON number or string, the number is parsed exactly as it's written
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 1 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		data = []byte(unquoted)
+	}
+
+	return m.UnmarshalText(data)
+}
+
+// withCurrency sets currency given in a separate field of the response, unless the amount has its own
+func (m *Money) withCurrency(currency string) {
+	if m.currency == "" {
+		m.currency = normalizeCurrency(currency)
+	}
+}
+
+// Sum adds up values, the sum of no values is the zero Money
+func Sum(values ...Money) (Money, error) {
+	total := Money{}
+	for _, value := range values {
+		var err error
+		if total, err = total.Add(value); err != nil {
+			return Money{}, err
+		}
+	}
+
+	return total, nil
+}
+
+// align returns both values with the same scale and currency
+func align(a, b Money) (Money, Money, error) {
+	if err := a.Err(); err != nil {
+		return Money{}, Money{}, err
+	}
+	if err := b.Err(); err != nil {
+		return Money{}, Money{}, err
+	}
+
+	switch {
+	case a.currency == b.currency:
+	case a.currency == "" && a.units == 0:
+		a.currency = b.currency
+	case b.currency == "" && b.units == 0:
+		b.currency = a.currency
+	default:
+		return Money{}, Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.currency, b.currency)
+	}
+
+	var err error
+	if a.scale < b.scale {
+		a, err = rescale(a, b.scale)
+	} else if b.scale < a.scale {
+		b, err = rescale(b, a.scale)
+	}
+
+	return a, b, err
+}
+
+func rescale(m Money, scale int32) (Money, error) {
+	mul := pow10(scale - m.scale)
+	if m.units > math.MaxInt64/mul || m.units < math.MinInt64/mul {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return Money{units: m.units * mul, scale: scale, currency: m.currency}, nil
+}
+
+func pow10(n int32) int64 {
+	res := int64(1)
+	for i := int32(0); i < n; i++ {
+		res *= 10
+	}
+
+	return res
+}
+
+func normalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
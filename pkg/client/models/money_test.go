@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+)
+
+func TestMoneyArithmetic(t *testing.T) {
+	a, _ := ParseMoney("0.1", "eur")
+	b, _ := ParseMoney("0,20", "EUR")
+	sum, err := a.Add(b)
+	if err != nil || sum.String() != "0.30 EUR" {
+		t.Errorf("0.1 + 0.20 = %s, %v", sum, err)
+	}
+
+	usd, _ := ParseMoney("1,234.50", "USD")
+	if _, err = a.Add(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("adding different currencies returned %v", err)
+	}
+	if _, err = a.Cmp(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("comparing different currencies returned %v", err)
+	}
+
+	if rounded := NewMoney(-125, 2, "EUR").Round(1); rounded.Amount() != "-1.3" {
+		t.Errorf("-1.25 rounded to %s", rounded.Amount())
+	}
+	if total, err := Sum(a, b, a); err != nil || total.Amount() != "0.40" {
+		t.Errorf("sum is %s, %v", total, err)
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount string
+		want   string
+		fails  bool
+	}{
+		{amount: "1234.50", want: "1234.50"},
+		{amount: "-0,5", want: "-0.5"},
+		{amount: "1,234.50", want: "1234.50"},
+		{amount: "1,234,567", want: "1234567"},
+		{amount: "0,205", want: "0.205"},
+		{amount: "1234,567", want: "1234.567"},
+		{amount: "12,34", want: "12.34"},
+		{amount: "1e3", want: "1000"},
+		{amount: "1.5E3", want: "1500"},
+		{amount: "1.25e1", want: "12.5"},
+		{amount: "-2.5e-2", want: "-0.025"},
+		{amount: "1E+2", want: "100"},
+		{amount: "1,234", fails: true},
+		{amount: "-12,500", fails: true},
+		{amount: "1e", fails: true},
+		{amount: "e3", fails: true},
+		{amount: "1e-30", fails: true},
+		{amount: "1e30", fails: true},
+		{amount: "N/A", fails: true},
+	}
+
+	for _, test := range tests {
+		m, err := ParseMoney(test.amount, "EUR")
+		if test.fails {
+			if err == nil {
+				t.Errorf("%q: got %s, want an error", test.amount, m)
+			}
+			continue
+		}
+		if err != nil || m.Amount() != test.want {
+			t.Errorf("%q: got %s, %v, want %s", test.amount, m.Amount(), err, test.want)
+		}
+	}
+}
+
+func TestMoneyKeepsUnparsedAmount(t *testing.T) {
+	offer := HotelSearchOffer{}
+	err := json.Unmarshal([]byte(`{"TotalPrice":1e3,"Currency":"EUR","TotalTax":"1,234","RoomRate":"N/A"}`), &offer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !offer.TotalPrice.Valid() || offer.TotalPrice.String() != "1000 EUR" {
+		t.Errorf("got total %s", offer.TotalPrice)
+	}
+	for _, m := range []Money{offer.TotalTax, offer.RoomRate} {
+		if m.Valid() || m.Err() == nil || !m.IsZero() || m.Currency() != "EUR" {
+			t.Errorf("%q: got valid %v, err %v, zero %v, currency %q", m.Raw(), m.Valid(), m.Err(), m.IsZero(), m.Currency())
+		}
+		if _, err = m.Add(offer.TotalPrice); err == nil {
+			t.Errorf("%q: adding an unparsed amount succeeded", m.Raw())
+		}
+		if text, _ := m.MarshalText(); string(text) != m.Raw() {
+			t.Errorf("%q: marshaled as %q", m.Raw(), text)
+		}
+	}
+	if offer.TotalTax.Raw() != "1,234" || offer.RoomRate.Raw() != "N/A" {
+		t.Errorf("got raw tax %q, rate %q", offer.TotalTax.Raw(), offer.RoomRate.Raw())
+	}
+
+	encoded, err := json.Marshal(offer.RoomRate)
+	if err != nil || string(encoded) != `"N/A"` {
+		t.Errorf("unparsed amount marshaled to JSON as %s, %v", encoded, err)
+	}
+}
+
+func TestHotelSearchOfferCurrency(t *testing.T) {
+	tests := map[string]func(*HotelSearchOffer) error{
+		"json": func(o *HotelSearchOffer) error {
+			return json.Unmarshal([]byte(`{"TotalPrice":100.10,"Currency":"EUR","TotalTax":"5","CommPercent":10,"CommValue":10.01}`), o)
+		},
+		"xml": func(o *HotelSearchOffer) error {
+			return xml.Unmarshal([]byte(`<Offer><TotalPrice>100.10</TotalPrice><Currency>EUR</Currency><TotalTax>5</TotalTax>`+
+				`<CommPercent>10</CommPercent><CommValue>10.01</CommValue></Offer>`), o)
+		},
+	}
+
+	for name, decode := range tests {
+		t.Run(name, func(t *testing.T) {
+			offer := HotelSearchOffer{}
+			if err := decode(&offer); err != nil {
+				t.Fatal(err)
+			}
+			if offer.TotalPrice.String() != "100.10 EUR" || offer.TotalTax.String() != "5 EUR" || offer.CommValue.String() != "10.01 EUR" {
+				t.Errorf("got total %s, tax %s, commission %s", offer.TotalPrice, offer.TotalTax, offer.CommValue)
+			}
+			if offer.CommPercent == nil || *offer.CommPercent != 10 {
+				t.Errorf("got commission percent %v", offer.CommPercent)
+			}
+		})
+	}
+}
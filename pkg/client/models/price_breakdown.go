@@ -18,14 +18,24 @@ type PriceBreakdownRoot struct {
 
 func (r PriceBreakdownRoot) CheckError() error {
 	if r.Header.OperationType == OperationTypeError || r.Header.OperationType == OperationTypeMessage {
-		return r.Main.ErrorResponse.Error
+		return NewSupplierError(r.Main.ErrorResponse)
 	}
 
 	return nil
 }
 
 func (r PriceBreakdownRoot) GetResponse() PriceBreakdownResponse {
-	return r.Main.PriceBreakdownResponse
+	response := r.Main.PriceBreakdownResponse
+	response.Room = append([]PriceBreakdownRoom(nil), response.Room...)
+	for i := range response.Room {
+		breakdown := append([]PriceBreakdown(nil), response.Room[i].PriceBreakdown...)
+		for j := range breakdown {
+			breakdown[j].Price.withCurrency(breakdown[j].Currency)
+		}
+		response.Room[i].PriceBreakdown = breakdown
+	}
+
+	return response
 }
 
 type PriceBreakdownMainResponse struct {
@@ -55,11 +65,11 @@ type PriceBreakdownRoom struct {
 type PriceBreakdown struct {
 	XMLName xml.Name `xml:"PriceBreakdown"`
 	//Break-down starting date (YYYY-mm-dd)
-	FromDate string `xml:"FromDate"`
+	FromDate Date `xml:"FromDate"`
 	//Break-down ending date(YYYY-mm-dd)
-	ToDate string `xml:"ToDate"`
+	ToDate Date `xml:"ToDate"`
 	//Price per 1 night	234.5
-	Price float64 `xml:"Price"`
+	Price Money `xml:"Price"`
 	//
 	Currency string
 }
@@ -25,7 +25,7 @@ type VoucherDetailsRoot struct {
 
 func (r VoucherDetailsRoot) CheckError() error {
 	if r.Header.OperationType == OperationTypeError || r.Header.OperationType == OperationTypeMessage {
-		return r.Main.ErrorResponse.Error
+		return NewSupplierError(r.Main.ErrorResponse)
 	}
 
 	return nil
@@ -54,7 +54,7 @@ type VoucherDetailsResponse struct {
 	//Hotel fax
 	Fax string `xml:"Fax"`
 	//checkin date (03/Mar/11)
-	CheckInDate string `xml:"CheckInDate"`
+	CheckInDate Date `xml:"CheckInDate"`
 	//Room bassis
 	RoomBasis string `xml:"RoomBasis"`
 	//Number of nights
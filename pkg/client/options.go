@@ -0,0 +1,12 @@
+package client
+
+type Option func(*goGlobalService)
+
+// WithRetryPolicy enables repeating of failed requests. Only operations safe to repeat are retried,
+// see RetryPolicy for details. Attempts are repeated inside the interceptor chain, so interceptors and
+// the call log see one call covering all attempts and the backoff between them
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *goGlobalService) {
+		s.retryPolicy = policy
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimitExceeded is returned when waiting for the rate limiter would outlast the context deadline
+var ErrRateLimitExceeded = errors.New("rate limit: wait exceeds context deadline")
+
+type RateLimit struct {
+	//Allowed requests per second, 0 - unlimited
+	Rate float64
+	//Max number of requests sent at once after an idle period, at least 1
+	Burst int
+}
+
+type RateLimiterConfig struct {
+	//Limit for HOTEL_SEARCH_REQUEST per agency
+	Search RateLimit
+	//Limit for every other operation per agency
+	Booking RateLimit
+	//Overrides by operation name, eg. BOOKING_STATUS_REQUEST
+	Operations map[string]RateLimit
+	//Called when a request had to wait for the limiter before being sent
+	OnWait func(ctx context.Context, agencyId int64, operation string, wait time.Duration)
+}
+
+// WithRateLimiter enables client side token bucket limiting keyed by Credentials.AgencyId and operation
+func WithRateLimiter(config RateLimiterConfig) Option {
+	return func(s *goGlobalService) {
+		s.rateLimiter = newRateLimiter(config)
+	}
+}
+
+type rateLimitKey struct {
+	agencyId  int64
+	operation goGlobalRequest
+}
+
+type rateLimiter struct {
+	config RateLimiterConfig
+
+	mu      sync.Mutex
+	buckets map[rateLimitKey]*tokenBucket
+}
+
+func newRateLimiter(config RateLimiterConfig) *rateLimiter {
+	return &rateLimiter{
+		config:  config,
+		buckets: map[rateLimitKey]*tokenBucket{},
+	}
+}
+
+func (l *rateLimiter) limit(operation goGlobalRequest) RateLimit {
+	if limit, ok := l.config.Operations[string(operation)]; ok {
+		return limit
+	}
+	if operation == searchRequest {
+		return l.config.Search
+	}
+
+	return l.config.Booking
+}
+
+// Wait blocks until the request can be sent
+func (l *rateLimiter) Wait(ctx context.Context, agencyId int64, operation goGlobalRequest) error {
+	limit := l.limit(operation)
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	key := rateLimitKey{agencyId: agencyId, operation: operation}
+	l.mu.Lock()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = newTokenBucket(limit)
+		l.buckets[key] = bucket
+	}
+	l.mu.Unlock()
+
+	wait, err := bucket.reserve(ctx, time.Now())
+	if err != nil || wait <= 0 {
+		return err
+	}
+
+	if l.config.OnWait != nil {
+		l.config.OnWait(ctx, agencyId, string(operation), wait)
+	}
+
+	if err = sleepContext(ctx, wait); err != nil {
+		bucket.cancel()
+		return err
+	}
+
+	return nil
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long the caller has to wait before using it.
+// Tokens may go negative, so concurrent waiters are queued one after another
+func (b *tokenBucket) reserve(ctx context.Context, now time.Time) (time.Duration, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+
+	var wait time.Duration
+	if b.tokens < 1 {
+		wait = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		return 0, ErrRateLimitExceeded
+	}
+
+	b.tokens--
+	return wait, nil
+}
+
+// cancel returns the token of a reservation that was not used
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	start := time.Now()
+	bucket := newTokenBucket(RateLimit{Rate: 10, Burst: 3})
+	bucket.last = start
+
+	//the burst is free, then waiters are queued a token apart
+	want := []time.Duration{0, 0, 0, 100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}
+	for i, w := range want {
+		wait, err := bucket.reserve(context.Background(), start)
+		if err != nil || wait != w {
+			t.Errorf("reservation %d: got %v, %v, want %v", i, wait, err, w)
+		}
+	}
+
+	//tokens refill with time, but never above the burst
+	bucket = newTokenBucket(RateLimit{Rate: 10, Burst: 2})
+	bucket.last = start
+	for i := 0; i < 2; i++ {
+		_, _ = bucket.reserve(context.Background(), start)
+	}
+	later := start.Add(time.Hour)
+	for i, w := range []time.Duration{0, 0, 100 * time.Millisecond} {
+		if wait, _ := bucket.reserve(context.Background(), later); wait != w {
+			t.Errorf("reservation %d after idle period: got %v, want %v", i, wait, w)
+		}
+	}
+}
+
+func TestTokenBucketDeadline(t *testing.T) {
+	start := time.Now()
+	bucket := newTokenBucket(RateLimit{Rate: 1, Burst: 1})
+	bucket.last = start
+	_, _ = bucket.reserve(context.Background(), start)
+
+	ctx, cancel := context.WithDeadline(context.Background(), start.Add(500*time.Millisecond))
+	defer cancel()
+	if _, err := bucket.reserve(ctx, start); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("got %v, want ErrRateLimitExceeded", err)
+	}
+
+	//the refused reservation took no token
+	if wait, err := bucket.reserve(context.Background(), start); err != nil || wait != time.Second {
+		t.Errorf("got %v, %v, want 1s", wait, err)
+	}
+
+	//a cancelled reservation gives its token back
+	bucket.cancel()
+	if wait, _ := bucket.reserve(context.Background(), start); wait != time.Second {
+		t.Errorf("got %v after cancel, want 1s", wait)
+	}
+}
+
+func TestRateLimiterKeys(t *testing.T) {
+	limiter := newRateLimiter(RateLimiterConfig{
+		Search:     RateLimit{Rate: 1, Burst: 1},
+		Booking:    RateLimit{Rate: 1, Burst: 2},
+		Operations: map[string]RateLimit{string(bookingStatus): {}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	tests := []struct {
+		agencyId  int64
+		operation goGlobalRequest
+		limited   bool
+	}{
+		{agencyId: 1, operation: searchRequest},
+		{agencyId: 1, operation: searchRequest, limited: true},
+		//buckets are per agency
+		{agencyId: 2, operation: searchRequest},
+		//and per operation
+		{agencyId: 1, operation: bookingValidation},
+		{agencyId: 1, operation: bookingValidation},
+		{agencyId: 1, operation: bookingValidation, limited: true},
+		{agencyId: 1, operation: bookingInsert},
+		//zero rate of the override is unlimited
+		{agencyId: 1, operation: bookingStatus},
+		{agencyId: 1, operation: bookingStatus},
+		{agencyId: 1, operation: bookingStatus},
+	}
+
+	for i, test := range tests {
+		err := limiter.Wait(ctx, test.agencyId, test.operation)
+		if limited := errors.Is(err, ErrRateLimitExceeded); limited != test.limited {
+			t.Errorf("request %d, agency %d, %s: got %v, want limited %v", i, test.agencyId, test.operation, err, test.limited)
+		}
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	var mu sync.Mutex
+	var waits []time.Duration
+	limiter := newRateLimiter(RateLimiterConfig{
+		Search: RateLimit{Rate: 50, Burst: 1},
+		OnWait: func(_ context.Context, agencyId int64, operation string, wait time.Duration) {
+			mu.Lock()
+			waits = append(waits, wait)
+			mu.Unlock()
+		},
+	})
+
+	start := time.Now()
+	wg := sync.WaitGroup{}
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = limiter.Wait(context.Background(), 1, searchRequest)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	//the first request goes at once, the other four 20ms after each other
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("5 requests at 50/s took %v, want at least 80ms", elapsed)
+	}
+	if len(waits) != 4 {
+		t.Errorf("OnWait called %d times, want 4", len(waits))
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := newRateLimiter(RateLimiterConfig{Search: RateLimit{Rate: 1, Burst: 1}})
+	if err := limiter.Wait(context.Background(), 1, searchRequest); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := limiter.Wait(ctx, 1, searchRequest); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+
+	//the cancelled waiter's token is given back, so the next one waits a token, not two
+	bucket := limiter.buckets[rateLimitKey{agencyId: 1, operation: searchRequest}]
+	if wait, _ := bucket.reserve(context.Background(), time.Now()); wait > time.Second {
+		t.Errorf("got wait %v, the cancelled reservation was kept", wait)
+	}
+}
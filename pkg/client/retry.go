@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+// RetryPolicy decides whether a failed attempt is repeated.
+// goGlobalService asks the policy only for operations that are safe to repeat
+// (see IsIdempotentOperation) or when the request provably never reached the server.
+type RetryPolicy interface {
+	// Backoff is called after a failed attempt (attempt counts from 1), statusCode is 0 for transport errors.
+	// Returns the delay before the next attempt and false if the request must not be repeated
+	Backoff(operation string, attempt int, statusCode int, err error) (time.Duration, bool)
+	// OnSuccess is called after a successful attempt
+	OnSuccess(operation string)
+}
+
+type RetryConfig struct {
+	//Max number of attempts including the first one
+	MaxAttempts int
+	//Delay before the first retry
+	InitialBackoff time.Duration
+	//Upper limit of the delay
+	MaxBackoff time.Duration
+	//Growth factor of the delay between attempts
+	Multiplier float64
+	//Random part of the delay 0..1, 0.2 means delay ±20%
+	Jitter float64
+	//Retry budget - max number of retry tokens, 0 - budget disabled
+	BudgetMaxTokens float64
+	//Retry budget - tokens returned to the budget by each successful attempt
+	BudgetTokenRatio float64
+}
+
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:      3,
+		InitialBackoff:   200 * time.Millisecond,
+		MaxBackoff:       5 * time.Second,
+		Multiplier:       2,
+		Jitter:           0.2,
+		BudgetMaxTokens:  10,
+		BudgetTokenRatio: 0.1,
+	}
+}
+
+type retryPolicy struct {
+	config RetryConfig
+
+	mu     sync.Mutex
+	tokens float64
+	rnd    *rand.Rand
+}
+
+// NewRetryPolicy returns RetryPolicy with exponential backoff and jitter.
+// Retries are paid from a shared budget: each retry takes one token, each success returns BudgetTokenRatio,
+// so during a supplier outage the client stops multiplying the load.
+func NewRetryPolicy(config RetryConfig) RetryPolicy {
+	if config.Multiplier < 1 {
+		config.Multiplier = 1
+	}
+	if config.Jitter < 0 {
+		config.Jitter = 0
+	}
+	if config.Jitter > 1 {
+		config.Jitter = 1
+	}
+
+	return &retryPolicy{
+		config: config,
+		tokens: config.BudgetMaxTokens,
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (p *retryPolicy) Backoff(_ string, attempt int, statusCode int, err error) (time.Duration, bool) {
+	if attempt >= p.config.MaxAttempts || !isRetryableFailure(statusCode, err) {
+		return 0, false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.config.BudgetMaxTokens > 0 {
+		if p.tokens < 1 {
+			return 0, false
+		}
+		p.tokens--
+	}
+
+	backoff := float64(p.config.InitialBackoff) * math.Pow(p.config.Multiplier, float64(attempt-1))
+	if p.config.MaxBackoff > 0 && backoff > float64(p.config.MaxBackoff) {
+		backoff = float64(p.config.MaxBackoff)
+	}
+	backoff += backoff * p.config.Jitter * (2*p.rnd.Float64() - 1)
+
+	return time.Duration(backoff), true
+}
+
+func (p *retryPolicy) OnSuccess(_ string) {
+	if p.config.BudgetMaxTokens <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.tokens += p.config.BudgetTokenRatio
+	if p.tokens > p.config.BudgetMaxTokens {
+		p.tokens = p.config.BudgetMaxTokens
+	}
+}
+
+// IsIdempotentOperation reports whether the operation (eg. HOTEL_SEARCH_REQUEST) can be safely repeated.
+// Booking insert, cancel and amendment change the reservation and are never repeated blindly.
+func IsIdempotentOperation(operation string) bool {
+	return idempotentRequests[goGlobalRequest(operation)]
+}
+
+func isRetryableFailure(statusCode int, err error) bool {
+	if err != nil {
+		return !isClientFault(err) &&
+			!errors.Is(err, context.Canceled) &&
+			!errors.Is(err, context.DeadlineExceeded) &&
+			!errors.Is(err, ErrRateLimitExceeded) &&
+			!errors.Is(err, ErrCircuitOpen)
+	}
+
+	return statusCode >= http.StatusInternalServerError ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode == http.StatusRequestTimeout
+}
+
+// isClientFault reports whether err is soap:Client fault, caused by the request and not by the endpoint health
+func isClientFault(err error) bool {
+	var fault *models.SoapFault
+	return errors.As(err, &fault) && fault.IsClient()
+}
+
+// isNotSentError reports whether the request could not reach the server, so even a non idempotent
+// operation can be repeated
+func isNotSentError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
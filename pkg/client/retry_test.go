@@ -0,0 +1,229 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+// scriptedResponse is one answer of scriptedHttpClient, err is returned instead of the response when set
+type scriptedResponse struct {
+	status int
+	body   string
+	err    error
+}
+
+// scriptedHttpClient answers requests with responses in order, the last one is repeated
+type scriptedHttpClient struct {
+	responses []scriptedResponse
+
+	mu       sync.Mutex
+	requests int
+}
+
+func (c *scriptedHttpClient) next() scriptedResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.requests
+	if i >= len(c.responses) {
+		i = len(c.responses) - 1
+	}
+	c.requests++
+
+	return c.responses[i]
+}
+
+func (c *scriptedHttpClient) Send(*http.Request) ([]byte, int, error) {
+	r := c.next()
+	if r.err != nil {
+		return nil, 0, r.err
+	}
+
+	return []byte(r.body), r.status, nil
+}
+
+func (c *scriptedHttpClient) Do(*http.Request) (*http.Response, error) {
+	r := c.next()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return &http.Response{StatusCode: r.status, Body: io.NopCloser(strings.NewReader(r.body))}, nil
+}
+
+func bookingStatusEnvelope() string {
+	return envelopeHead + html.EscapeString(`<Root><Header><OperationType>Response</OperationType></Header><Main>`+
+		`<GoBookingCode Status="C" TotalPrice="10.50" Currency="EUR">123</GoBookingCode></Main></Root>`) + envelopeTail
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := NewRetryPolicy(RetryConfig{
+		MaxAttempts:    4,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     300 * time.Millisecond,
+		Multiplier:     2,
+	})
+
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond} {
+		backoff, retry := policy.Backoff(string(searchRequest), attempt+1, http.StatusServiceUnavailable, nil)
+		if !retry || backoff != want {
+			t.Errorf("attempt %d: got %v, %v, want %v", attempt+1, backoff, retry, want)
+		}
+	}
+	if _, retry := policy.Backoff(string(searchRequest), 4, http.StatusServiceUnavailable, nil); retry {
+		t.Error("retried after MaxAttempts")
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		retry  bool
+	}{
+		{name: "503", status: http.StatusServiceUnavailable, retry: true},
+		{name: "429", status: http.StatusTooManyRequests, retry: true},
+		{name: "transport", err: &TransportError{Err: errors.New("connection reset")}, retry: true},
+		{name: "soap:Server", status: http.StatusInternalServerError, err: &models.SoapFault{Code: "soap:Server"}, retry: true},
+		{name: "400", status: http.StatusBadRequest},
+		{name: "soap:Client", status: http.StatusInternalServerError, err: &models.SoapFault{Code: "soap:Client"}},
+		{name: "canceled", err: context.Canceled},
+		{name: "deadline", err: context.DeadlineExceeded},
+		{name: "circuit open", err: ErrCircuitOpen},
+		{name: "rate limit", err: ErrRateLimitExceeded},
+	}
+	for _, test := range tests {
+		if _, retry := policy.Backoff(string(searchRequest), 1, test.status, test.err); retry != test.retry {
+			t.Errorf("%s: got retry %v, want %v", test.name, retry, test.retry)
+		}
+	}
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	policy := NewRetryPolicy(RetryConfig{MaxAttempts: 2, InitialBackoff: 100 * time.Millisecond, Jitter: 0.2})
+
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 1000; i++ {
+		backoff, retry := policy.Backoff(string(searchRequest), 1, http.StatusBadGateway, nil)
+		if !retry || backoff < 80*time.Millisecond || backoff > 120*time.Millisecond {
+			t.Fatalf("got %v, %v, want 100ms ±20%%", backoff, retry)
+		}
+		seen[backoff] = true
+	}
+	if len(seen) < 100 {
+		t.Errorf("got %d distinct delays of 1000, jitter is not applied", len(seen))
+	}
+}
+
+func TestRetryPolicyBudget(t *testing.T) {
+	policy := NewRetryPolicy(RetryConfig{MaxAttempts: 10, BudgetMaxTokens: 2, BudgetTokenRatio: 0.5})
+	retry := func() bool {
+		_, ok := policy.Backoff(string(searchRequest), 1, http.StatusBadGateway, nil)
+		return ok
+	}
+
+	if !retry() || !retry() {
+		t.Fatal("retries within the budget were refused")
+	}
+	if retry() {
+		t.Fatal("retried with an empty budget")
+	}
+
+	policy.OnSuccess(string(searchRequest))
+	if retry() {
+		t.Fatal("retried with half a token")
+	}
+	policy.OnSuccess(string(searchRequest))
+	policy.OnSuccess(string(searchRequest))
+	if !retry() {
+		t.Fatal("budget was not refilled by successes")
+	}
+
+	//the budget never grows above its max
+	for i := 0; i < 100; i++ {
+		policy.OnSuccess(string(searchRequest))
+	}
+	if !retry() || !retry() || retry() {
+		t.Error("budget grew above BudgetMaxTokens")
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	unavailable := scriptedResponse{status: http.StatusServiceUnavailable, body: "busy"}
+	ok := scriptedResponse{status: http.StatusOK, body: bookingStatusEnvelope()}
+
+	status := func(service GoGlobalService) error {
+		_, err := service.BookingStatus(context.Background(), Credentials{}, models.BookingStatusRequest{GoBookingCode: "123"})
+		return err
+	}
+	insert := func(service GoGlobalService) error {
+		_, err := service.BookingInsert(context.Background(), Credentials{}, models.BookingInsertRequest{HotelSearchCode: "1/1"})
+		return err
+	}
+
+	tests := []struct {
+		name      string
+		call      func(GoGlobalService) error
+		responses []scriptedResponse
+		requests  int
+		failed    bool
+	}{
+		{name: "idempotent recovers", call: status, responses: []scriptedResponse{unavailable, unavailable, ok}, requests: 3},
+		{name: "idempotent gives up", call: status, responses: []scriptedResponse{unavailable}, requests: 3, failed: true},
+		{name: "idempotent transport error", call: status, responses: []scriptedResponse{{err: readErr}, ok}, requests: 2},
+		{name: "not idempotent", call: insert, responses: []scriptedResponse{unavailable, ok}, requests: 1, failed: true},
+		{name: "not idempotent, read error", call: insert, responses: []scriptedResponse{{err: readErr}, ok}, requests: 1, failed: true},
+		{name: "not idempotent, not sent", call: insert, responses: []scriptedResponse{{err: dialErr}, ok}, requests: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpClient := &scriptedHttpClient{responses: test.responses}
+			config := DefaultRetryConfig()
+			config.InitialBackoff = time.Millisecond
+			service := NewGoGlobalService("http://localhost", httpClient, WithRetryPolicy(NewRetryPolicy(config)))
+
+			if err := test.call(service); (err != nil) != test.failed {
+				t.Errorf("got error %v, want failure %v", err, test.failed)
+			}
+			if httpClient.requests != test.requests {
+				t.Errorf("got %d requests, want %d", httpClient.requests, test.requests)
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnContextDone(t *testing.T) {
+	httpClient := &scriptedHttpClient{responses: []scriptedResponse{{status: http.StatusServiceUnavailable}}}
+	config := DefaultRetryConfig()
+	config.InitialBackoff = time.Hour
+	config.MaxBackoff = time.Hour
+	service := NewGoGlobalService("http://localhost", httpClient, WithRetryPolicy(NewRetryPolicy(config)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := service.BookingStatus(ctx, Credentials{}, models.BookingStatusRequest{GoBookingCode: "123"})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("backoff outlived the context: %v", elapsed)
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if httpClient.requests != 1 {
+		t.Errorf("got %d requests, want 1", httpClient.requests)
+	}
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+const (
+	//time left for our side of the request when MaximumWaitTime is derived from the context deadline
+	searchWaitMargin = 2 * time.Second
+)
+
+type SearchCitiesConfig struct {
+	//Cities per request, <= 0 means 1
+	CitiesPerRequest int
+	//Max concurrent requests, <= 0 means DefaultSearchParallelism
+	Parallelism int
+}
+
+// CitySearch reports one request of SearchCities
+type CitySearch struct {
+	CityCode []int64
+	Duration time.Duration
+	//Number of hotels returned by the request, before MaxResponses is applied
+	Hotels int
+	Err    error
+}
+
+type SearchCitiesResult struct {
+	//Hotels of all cities sorted by request.SortOrder
+	Hotels []models.HotelSearchResponseItem
+	//Summed stats of the succeeded requests
+	Stats models.SearchStats
+	//Per request timing and failures, in order of request.CityCode
+	Cities []CitySearch
+}
+
+// SearchCities searches request.CityCode issuing a request per CitiesPerRequest cities in parallel.
+// MaximumWaitTime of each request is lowered to fit ctx deadline when the request starts, so the supplier
+// returns what it has before the deadline instead of the request failing. Hotels are merged, sorted by SortOrder
+// and MaxResponses is applied to the whole result. When some requests fail the hotels of the others are returned along with *PartialSearchError
+func (c *goGlobalService) SearchCities(
+	ctx context.Context,
+	credentials Credentials,
+	request models.HotelSearchRequest,
+	config SearchCitiesConfig,
+) (result SearchCitiesResult, err error) {
+	ctx, span := c.startSpan(ctx, searchCitiesOperation, credentials, nil)
+	defer func() {
+		endSpan(span, nil, err)
+	}()
+
+	perRequest := config.CitiesPerRequest
+	if perRequest <= 0 {
+		perRequest = 1
+	}
+
+	cities := uniqueIds(request.CityCode)
+	var requests []models.HotelSearchRequest
+	for len(cities) > 0 {
+		n := perRequest
+		if n > len(cities) {
+			n = len(cities)
+		}
+		cityRequest := request
+		cityRequest.CityCode = cities[:n:n]
+		requests = append(requests, cityRequest)
+		cities = cities[n:]
+	}
+
+	outcomes := c.searchConcurrently(ctx, credentials, requests, config.Parallelism, true)
+
+	result = SearchCitiesResult{Cities: make([]CitySearch, 0, len(requests))}
+	var failures []SearchFailure
+	for i, outcome := range outcomes {
+		result.Cities = append(result.Cities, CitySearch{
+			CityCode: requests[i].CityCode,
+			Duration: outcome.duration,
+			Hotels:   len(outcome.response.Hotels),
+			Err:      outcome.err,
+		})
+		if outcome.err != nil {
+			failures = append(failures, SearchFailure{CityCode: requests[i].CityCode, Err: outcome.err})
+			continue
+		}
+		result.Hotels = append(result.Hotels, outcome.response.Hotels...)
+		result.Stats.HotelQty += outcome.response.Header.Stats.HotelQty
+		result.Stats.ResultsQty += outcome.response.Header.Stats.ResultsQty
+	}
+
+	SortHotels(result.Hotels, request.SortOrder)
+	result.Hotels = limitResults(result.Hotels, request.MaxResponses)
+
+	if len(failures) > 0 {
+		return result, &PartialSearchError{Failures: failures, Requests: len(requests)}
+	}
+
+	return result, nil
+}
+
+// maximumWaitTime returns wait time in seconds fitting ctx deadline, requested is kept when it fits
+func maximumWaitTime(ctx context.Context, requested int64) int64 {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return requested
+	}
+
+	available := int64((time.Until(deadline) - searchWaitMargin) / time.Second)
+	if available < 1 {
+		available = 1
+	}
+	if requested > 0 && requested < available {
+		return requested
+	}
+
+	return available
+}
+
+// SortHotels sorts offers of each hotel and then hotels by their first offer according to sortOrder
+// (models.SortByPriceAsc, etc.), other orders keep hotels as they are. The sort is stable
+func SortHotels(hotels []models.HotelSearchResponseItem, sortOrder string) {
+	less := offerLess(sortOrder)
+	if less == nil {
+		return
+	}
+
+	for i := range hotels {
+		offers := hotels[i].Offers
+		sort.SliceStable(offers, func(a, b int) bool {
+			return less(&offers[a], &offers[b])
+		})
+	}
+
+	sort.SliceStable(hotels, func(a, b int) bool {
+		if len(hotels[a].Offers) == 0 || len(hotels[b].Offers) == 0 {
+			return len(hotels[a].Offers) > len(hotels[b].Offers)
+		}
+		return less(&hotels[a].Offers[0], &hotels[b].Offers[0])
+	})
+}
+
+func offerLess(sortOrder string) func(a, b *models.HotelSearchOffer) bool {
+	switch sortOrder {
+	case models.SortByPriceAsc:
+		return func(a, b *models.HotelSearchOffer) bool {
+			return priceCmp(a, b) < 0
+		}
+	case models.SortByPriceDesc:
+		return func(a, b *models.HotelSearchOffer) bool {
+			return priceCmp(a, b) > 0
+		}
+	case models.SortByCxlAsc:
+		return func(a, b *models.HotelSearchOffer) bool {
+			return cxlDeadlineUnix(a.CxlDeadline, math.MaxInt64) < cxlDeadlineUnix(b.CxlDeadline, math.MaxInt64)
+		}
+	case models.SortByCxlDesc:
+		return func(a, b *models.HotelSearchOffer) bool {
+			return cxlDeadlineUnix(a.CxlDeadline, math.MinInt64) > cxlDeadlineUnix(b.CxlDeadline, math.MinInt64)
+		}
+	}
+
+	return nil
+}
+
+// priceCmp compares total prices of offers, offers in different currencies are ordered by currency
+func priceCmp(a, b *models.HotelSearchOffer) int {
+	cmp, err := a.TotalPrice.Cmp(b.TotalPrice)
+	if err != nil {
+		return strings.Compare(a.TotalPrice.Currency(), b.TotalPrice.Currency())
+	}
+
+	return cmp
+}
+
+// cxlDeadlineUnix returns cancellation deadline as unix time, missing deadlines get fallback to go last
+func cxlDeadlineUnix(deadline models.Date, fallback int64) int64 {
+	if deadline.IsZero() {
+		return fallback
+	}
+
+	return deadline.Time().Unix()
+}
+
+// limitResults keeps first maxResponses offers in hotels order, maxResponses <= 0 means no limit
+func limitResults(hotels []models.HotelSearchResponseItem, maxResponses int64) []models.HotelSearchResponseItem {
+	if maxResponses <= 0 {
+		return hotels
+	}
+
+	left := int(maxResponses)
+	for i := range hotels {
+		if left == 0 {
+			return hotels[:i]
+		}
+		if len(hotels[i].Offers) > left {
+			hotels[i].Offers = hotels[i].Offers[:left]
+		}
+		left -= len(hotels[i].Offers)
+	}
+
+	return hotels
+}
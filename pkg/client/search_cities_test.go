@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+var sentWaitTime = regexp.MustCompile(`<MaximumWaitTime>(\d+)</MaximumWaitTime>`)
+
+func TestSearchCitiesWaitTimeFitsDeadline(t *testing.T) {
+	httpClient := &fakeHttpClient{
+		status: http.StatusOK,
+		body:   envelopeHead + html.EscapeString(jsonSearchPayload(1)) + envelopeTail,
+		delay:  time.Second,
+	}
+	service := streamService(httpClient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), searchWaitMargin+2500*time.Millisecond)
+	defer cancel()
+
+	result, err := service.SearchCities(ctx, Credentials{}, models.HotelSearchRequest{CityCode: []int64{1, 2}},
+		SearchCitiesConfig{Parallelism: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Cities) != 2 {
+		t.Fatalf("got %d requests, want 2", len(result.Cities))
+	}
+
+	//the second request waits for the first one, so it has a second less
+	want := []string{"2", "1"}
+	for i, sent := range httpClient.sent {
+		match := sentWaitTime.FindStringSubmatch(sent)
+		if match == nil || match[1] != want[i] {
+			t.Errorf("request %d sent MaximumWaitTime %v, want %s", i, match, want[i])
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+// DefaultSearchParallelism number of concurrent supplier requests of a split search
+const DefaultSearchParallelism = 4
+
+type SearchManyConfig struct {
+	//Hotel ids per request, <= 0 or more than models.MaxSearchHotelIds means models.MaxSearchHotelIds
+	ChunkSize int
+	//Max concurrent requests, <= 0 means DefaultSearchParallelism
+	Parallelism int
+}
+
+// SearchFailure is a failed part of a search split into several supplier requests
+type SearchFailure struct {
+	//Hotel ids of the failed request
+	HotelIds []int64
+	//City codes of the failed request
+	CityCode []int64
+	Err      error
+}
+
+// PartialSearchError is returned along with results of the succeeded parts when some parts of a split search failed.
+// errors.Is and errors.As match errors of any part
+type PartialSearchError struct {
+	Failures []SearchFailure
+	//Total number of requests of the search
+	Requests int
+}
+
+func (e *PartialSearchError) Error() string {
+	msgs := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		msgs = append(msgs, failure.Err.Error())
+	}
+
+	return fmt.Sprintf("%s: %d of %d requests failed: %s", searchRequest, len(e.Failures), e.Requests, strings.Join(msgs, "; "))
+}
+
+func (e *PartialSearchError) Is(target error) bool {
+	for _, failure := range e.Failures {
+		if errors.Is(failure.Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *PartialSearchError) As(target interface{}) bool {
+	for _, failure := range e.Failures {
+		if errors.As(failure.Err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SearchMany searches request.Hotels split into chunks accepted by the supplier, running the chunks concurrently.
+// Hotels of all chunks are merged in chunk order and their stats are summed. When some chunks fail
+// the hotels of the others are returned along with *PartialSearchError
+func (c *goGlobalService) SearchMany(
+	ctx context.Context,
+	credentials Credentials,
+	request models.HotelSearchRequest,
+	config SearchManyConfig,
+) (hotels []models.HotelSearchResponseItem, stats models.SearchStats, err error) {
+	ctx, span := c.startSpan(ctx, searchManyOperation, credentials, nil)
+	defer func() {
+		endSpan(span, nil, err)
+	}()
+
+	chunks := models.SplitHotelIds(uniqueIds(request.Hotels.HotelId), config.ChunkSize)
+	if len(chunks) <= 1 {
+		if len(chunks) == 1 {
+			request.Hotels = chunks[0]
+		}
+		results, err := c.search(ctx, credentials, request)
+		if err != nil {
+			return nil, models.SearchStats{}, err
+		}
+		return results.Hotels, results.Header.Stats, nil
+	}
+
+	requests := make([]models.HotelSearchRequest, 0, len(chunks))
+	for _, chunk := range chunks {
+		chunkRequest := request
+		chunkRequest.Hotels = chunk
+		requests = append(requests, chunkRequest)
+	}
+
+	outcomes := c.searchConcurrently(ctx, credentials, requests, config.Parallelism, false)
+
+	var failures []SearchFailure
+	for i, outcome := range outcomes {
+		if outcome.err != nil {
+			failures = append(failures, SearchFailure{HotelIds: requests[i].Hotels.HotelId, Err: outcome.err})
+			continue
+		}
+		hotels = append(hotels, outcome.response.Hotels...)
+		stats.HotelQty += outcome.response.Header.Stats.HotelQty
+		stats.ResultsQty += outcome.response.Header.Stats.ResultsQty
+	}
+
+	if len(failures) > 0 {
+		return hotels, stats, &PartialSearchError{Failures: failures, Requests: len(requests)}
+	}
+
+	return hotels, stats, nil
+}
+
+type searchOutcome struct {
+	response models.HotelSearchResponse
+	err      error
+	duration time.Duration
+}
+
+// searchConcurrently runs requests with at most parallelism of them in flight. With fitDeadline MaximumWaitTime
+// of each request is lowered to the time left until ctx deadline when the request starts, and the request
+// is limited by a timeout of its wait time. Outcomes are indexed as requests
+func (c *goGlobalService) searchConcurrently(
+	ctx context.Context,
+	credentials Credentials,
+	requests []models.HotelSearchRequest,
+	parallelism int,
+	fitDeadline bool,
+) []searchOutcome {
+	if parallelism <= 0 {
+		parallelism = DefaultSearchParallelism
+	}
+
+	outcomes := make([]searchOutcome, len(requests))
+	slots := make(chan struct{}, parallelism)
+	wg := sync.WaitGroup{}
+
+	for i := range requests {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			outcomes[i].err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			request, requestCtx := requests[i], ctx
+			if fitDeadline {
+				request.MaximumWaitTime = maximumWaitTime(ctx, request.MaximumWaitTime)
+			}
+			if fitDeadline && request.MaximumWaitTime > 0 {
+				var cancel context.CancelFunc
+				requestCtx, cancel = context.WithTimeout(ctx, time.Duration(request.MaximumWaitTime)*time.Second+searchWaitMargin)
+				defer cancel()
+			}
+			start := time.Now()
+			outcomes[i].response, outcomes[i].err = c.search(requestCtx, credentials, request)
+			outcomes[i].duration = time.Since(start)
+		}(i)
+	}
+	wg.Wait()
+
+	return outcomes
+}
+
+func uniqueIds(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	res := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			res = append(res, id)
+		}
+	}
+
+	return res
+}
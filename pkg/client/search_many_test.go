@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+func TestSearchManySentIds(t *testing.T) {
+	ids := func(n int, repeat int) []int64 {
+		res := make([]int64, 0, n*repeat)
+		for r := 0; r < repeat; r++ {
+			for i := 1; i <= n; i++ {
+				res = append(res, int64(i))
+			}
+		}
+		return res
+	}
+
+	tests := []struct {
+		name      string
+		ids       []int64
+		chunkSize int
+		want      []int
+	}{
+		{name: "duplicates in one chunk", ids: ids(300, 2), want: []int{300}},
+		{name: "below chunk size", ids: ids(20, 1), chunkSize: 50, want: []int{20}},
+		{name: "equal to chunk size", ids: ids(50, 1), chunkSize: 50, want: []int{50}},
+		{name: "above chunk size", ids: ids(120, 1), chunkSize: 50, want: []int{50, 50, 20}},
+		{name: "duplicates above chunk size", ids: ids(120, 3), chunkSize: 50, want: []int{50, 50, 20}},
+		{name: "above supplier limit", ids: ids(1200, 1), want: []int{500, 500, 200}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpClient := &fakeHttpClient{status: http.StatusOK, body: envelopeHead + html.EscapeString(jsonSearchPayload(1)) + envelopeTail}
+			service := streamService(httpClient)
+
+			_, _, err := service.SearchMany(context.Background(), Credentials{},
+				models.HotelSearchRequest{Hotels: models.SearchHotels{HotelId: test.ids}},
+				SearchManyConfig{ChunkSize: test.chunkSize, Parallelism: 1},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(httpClient.sent) != len(test.want) {
+				t.Fatalf("got %d requests, want %d", len(httpClient.sent), len(test.want))
+			}
+			for i, want := range test.want {
+				if got := strings.Count(httpClient.sent[i], "<HotelId>"); got != want {
+					t.Errorf("request %d sent %d ids, want %d", i, got, want)
+				}
+			}
+		})
+	}
+}
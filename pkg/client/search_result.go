@@ -0,0 +1,56 @@
+package client
+
+import (
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+// SearchResult is a search response with its header and request metadata
+type SearchResult struct {
+	Hotels []models.HotelSearchResponseItem
+	//Response header, Stats are copied to SearchResult.Stats
+	Header models.Header
+	Stats  models.SearchStats
+	//Request version actually sent
+	Version string
+	//Client side time of the whole call including retries, backoff and waits for the rate limiter.
+	//The supplier does not report its own search timing
+	ClientLatency time.Duration
+	//Results limit of the request, 0 when it's not set
+	MaxResponses int64
+	//Hotels limit of the request, 0 when it's not set
+	MaxHotels int64
+}
+
+func newSearchResult(request models.HotelSearchRequest, response models.HotelSearchResponse, latency time.Duration) SearchResult {
+	return SearchResult{
+		Hotels:        response.Hotels,
+		Header:        response.Header,
+		Stats:         response.Header.Stats,
+		Version:       searchVersion(request),
+		ClientLatency: latency,
+		MaxResponses:  request.MaxResponses,
+		MaxHotels:     request.MaxHotels,
+	}
+}
+
+// Offers returns number of offers of all hotels
+func (r SearchResult) Offers() int {
+	return countOffers(r.Hotels)
+}
+
+// Truncated reports whether the supplier likely returned less than it has: stats report more than was returned
+// or one of the limits set in the request was reached. A limit the supplier may apply on its own is not known,
+// so it's not detected
+func (r SearchResult) Truncated() bool {
+	offers := r.Offers()
+	if r.Stats.HotelQty > len(r.Hotels) || r.Stats.ResultsQty > offers {
+		return true
+	}
+	if r.MaxResponses > 0 && int64(offers) >= r.MaxResponses {
+		return true
+	}
+
+	return r.MaxHotels > 0 && int64(len(r.Hotels)) >= r.MaxHotels
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+func TestSearchResultTruncated(t *testing.T) {
+	hotels := func(n, offers int) []models.HotelSearchResponseItem {
+		res := make([]models.HotelSearchResponseItem, n)
+		for i := range res {
+			res[i].Offers = make([]models.HotelSearchOffer, offers)
+		}
+		return res
+	}
+
+	tests := []struct {
+		name   string
+		result SearchResult
+		want   bool
+	}{
+		{name: "no limits", result: SearchResult{Hotels: hotels(1000, 1)}},
+		{name: "below limits", result: SearchResult{Hotels: hotels(3, 2), MaxResponses: 10, MaxHotels: 5}},
+		{name: "results limit reached", result: SearchResult{Hotels: hotels(5, 2), MaxResponses: 10}, want: true},
+		{name: "hotels limit reached", result: SearchResult{Hotels: hotels(5, 2), MaxHotels: 5}, want: true},
+		{name: "stats report more hotels", result: SearchResult{Hotels: hotels(2, 1), Stats: models.SearchStats{HotelQty: 3}}, want: true},
+		{name: "stats report more results", result: SearchResult{Hotels: hotels(2, 1), Stats: models.SearchStats{HotelQty: 2, ResultsQty: 4}}, want: true},
+		{name: "stats match", result: SearchResult{Hotels: hotels(2, 2), Stats: models.SearchStats{HotelQty: 2, ResultsQty: 4}}},
+	}
+
+	for _, test := range tests {
+		if got := test.result.Truncated(); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSearchDetailed(t *testing.T) {
+	httpClient := &fakeHttpClient{
+		status: http.StatusOK,
+		body:   envelopeHead + html.EscapeString(jsonSearchPayload(3)) + envelopeTail,
+		delay:  time.Millisecond,
+	}
+	service := streamService(httpClient)
+
+	tests := []struct {
+		request models.HotelSearchRequest
+		version string
+	}{
+		{request: models.HotelSearchRequest{}, version: defaultRequestVersion[searchRequest]},
+		{request: models.HotelSearchRequest{Version: "2.2", MaxResponses: 3, MaxHotels: 10}, version: "2.2"},
+	}
+
+	for _, test := range tests {
+		result, err := service.SearchDetailed(context.Background(), Credentials{}, test.request)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result.Hotels) != 3 || result.Stats.HotelQty != 3 || result.Header.OperationType != models.OperationTypeResponse {
+			t.Errorf("got %d hotels, stats %+v, header %+v", len(result.Hotels), result.Stats, result.Header)
+		}
+		if result.Version != test.version {
+			t.Errorf("got version %q, want %q", result.Version, test.version)
+		}
+		if result.MaxResponses != test.request.MaxResponses || result.MaxHotels != test.request.MaxHotels {
+			t.Errorf("got limits %d, %d", result.MaxResponses, result.MaxHotels)
+		}
+		if result.ClientLatency < httpClient.delay {
+			t.Errorf("got latency %v", result.ClientLatency)
+		}
+		if result.Truncated() != (test.request.MaxResponses > 0) {
+			t.Errorf("got truncated %v", result.Truncated())
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+// SearchStream is Search decoding hotels one by one while the response is downloaded and passing them to fn
+// instead of collecting them, so the whole result is never held in memory and the first hotels are available
+// before the rest arrives. Every hotel is a new value, fn may keep it. An error returned by fn stops decoding
+// and is returned to the caller. Attempts are repeated by the retry policy only until the response starts
+// to be decoded. Interceptors don't see the response body, it's decoded as it arrives
+func (c *goGlobalService) SearchStream(
+	ctx context.Context,
+	credentials Credentials,
+	request models.HotelSearchRequest,
+	fn func(*models.HotelSearchResponseItem) error,
+) (err error) {
+	request.Version = searchVersion(request)
+
+	ctx, span := c.startSpan(ctx, string(searchRequest), credentials, request)
+	start := time.Now()
+	size := 0
+	hotels, offers := 0, 0
+	defer func() {
+		endSpan(span, nil, err)
+		c.observeRequest(searchRequest, start, size, err)
+		if err == nil && c.metrics != nil {
+			c.metrics.ObserveSearch(hotels, offers)
+		}
+	}()
+
+	size, err = c.doStreamRequest(ctx, credentials, searchRequest, request, func(payload io.Reader) error {
+		_, err := decodeSearchResponse(payload, func(hotel *models.HotelSearchResponseItem) error {
+			hotels++
+			offers += len(hotel.Offers)
+			return fn(hotel)
+		})
+		return err
+	})
+
+	return err
+}
+
+// decodeSearchResponse streams hotels of JSON or XML search payload to fn and returns the response header.
+// Supplier errors reported in the payload are returned as *models.SupplierError
+func decodeSearchResponse(payload io.Reader, fn func(*models.HotelSearchResponseItem) error) (models.Header, error) {
+	tail := &tailReader{r: payload}
+	r := bufio.NewReader(tail)
+
+	//accounts provisioned for XML only ignore ResponseFormat, so the format is taken from the payload
+	if responseFormat(r) == FormatXml {
+		return decodeSearchXml(r, tail, fn)
+	}
+
+	return decodeSearchJson(r, tail, fn)
+}
+
+// responseFormat returns FormatXml when payload starts with a tag, FormatJson otherwise. Nothing is consumed
+func responseFormat(r *bufio.Reader) string {
+	for i := 1; ; i++ {
+		head, err := r.Peek(i)
+		if err != nil {
+			return FormatJson
+		}
+		trimmed := bytes.TrimLeft(head, "\ufeff \t\r\n")
+		if len(trimmed) > 0 && utf8.FullRune(trimmed) {
+			if trimmed[0] == '<' {
+				return FormatXml
+			}
+			return FormatJson
+		}
+	}
+}
+
+// tailReader keeps the last read bytes to show them in DecodeError of a streamed payload
+type tailReader struct {
+	r io.Reader
+	//offset of tail[0] in the payload
+	offset int64
+	tail   []byte
+}
+
+const tailReaderSize = 64 * 1024
+
+func (t *tailReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	t.tail = append(t.tail, p[:n]...)
+	if extra := len(t.tail) - tailReaderSize; extra > tailReaderSize {
+		t.tail = append(t.tail[:0], t.tail[extra:]...)
+		t.offset += int64(extra)
+	}
+
+	return n, err
+}
+
+// decodeError returns DecodeError with a snippet around offset, if it's still kept
+func (t *tailReader) decodeError(format string, offset int64, err error) *DecodeError {
+	if offset < t.offset {
+		res := newDecodeError(string(searchRequest), format, nil, 0, err)
+		res.Offset = offset
+		return res
+	}
+
+	res := newDecodeError(string(searchRequest), format, t.tail, offset-t.offset, err)
+	res.Offset = offset
+
+	return res
+}
+
+func decodeSearchJson(r io.Reader, tail *tailReader, fn func(*models.HotelSearchResponseItem) error) (models.Header, error) {
+	decoder := json.NewDecoder(r)
+	decodeErr := func(err error) error {
+		return tail.decodeError(FormatJson, decoder.InputOffset(), err)
+	}
+
+	results := models.HotelSearchResponse{}
+	if err := expectJsonDelim(decoder, '{'); err != nil {
+		return models.Header{}, decodeErr(err)
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return models.Header{}, decodeErr(err)
+		}
+
+		switch token {
+		case "Hotels":
+			if err = streamJsonHotels(decoder, fn, decodeErr); err != nil {
+				return models.Header{}, err
+			}
+		case "Header":
+			err = decoder.Decode(&results.Header)
+		case "Main":
+			err = decoder.Decode(&results.Main)
+		default:
+			var skip json.RawMessage
+			err = decoder.Decode(&skip)
+		}
+		if err != nil {
+			return models.Header{}, decodeErr(err)
+		}
+	}
+
+	if results.Header.OperationType == models.OperationTypeError || results.Header.OperationType == models.OperationTypeMessage {
+		return models.Header{}, models.NewSupplierError(results.Main)
+	}
+
+	return results.Header, nil
+}
+
+// streamJsonHotels decodes Hotels array, null is treated as empty array
+func streamJsonHotels(
+	decoder *json.Decoder,
+	fn func(*models.HotelSearchResponseItem) error,
+	decodeErr func(error) error,
+) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return decodeErr(err)
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('[') {
+		return decodeErr(fmt.Errorf("unexpected %v, expecting array of hotels", token))
+	}
+
+	for decoder.More() {
+		hotel := &models.HotelSearchResponseItem{}
+		if err = decoder.Decode(hotel); err != nil {
+			return decodeErr(err)
+		}
+		if err = fn(hotel); err != nil {
+			return err
+		}
+	}
+
+	if err = expectJsonDelim(decoder, ']'); err != nil {
+		return decodeErr(err)
+	}
+
+	return nil
+}
+
+func expectJsonDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("unexpected %v, expecting %v", token, delim)
+	}
+
+	return nil
+}
+
+// decodeSearchXml walks Root/Main decoding Hotel elements one by one, everything else but Header and errors is skipped
+func decodeSearchXml(r io.Reader, tail *tailReader, fn func(*models.HotelSearchResponseItem) error) (models.Header, error) {
+	decoder := xml.NewDecoder(newXmlSanitizer(r))
+	decodeErr := func(err error) error {
+		return tail.decodeError(FormatXml, decoder.InputOffset(), err)
+	}
+
+	root := models.HotelSearchRoot{}
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return models.Header{}, decodeErr(err)
+		}
+
+		switch t := token.(type) {
+		case xml.EndElement:
+			depth--
+			continue
+		case xml.StartElement:
+			depth++
+			//elements below are decoded or skipped as a whole, so the depth is restored right away
+			switch {
+			case depth == 1 || (depth == 2 && t.Name.Local == "Main"):
+				continue
+			case depth == 2 && t.Name.Local == "Header":
+				err = decoder.DecodeElement(&root.Header, &t)
+			case depth == 3 && t.Name.Local == "Hotel":
+				hotel := &models.HotelSearchResponseItem{}
+				if err = decoder.DecodeElement(hotel, &t); err != nil {
+					return models.Header{}, decodeErr(err)
+				}
+				if err = fn(hotel); err != nil {
+					return models.Header{}, err
+				}
+			case depth == 3 && t.Name.Local == "Error":
+				err = decoder.DecodeElement(&root.Main.Error, &t)
+			case depth == 3 && t.Name.Local == "DebugError":
+				err = decoder.DecodeElement(&root.Main.DebugError, &t)
+			default:
+				err = decoder.Skip()
+			}
+			depth--
+			if err != nil {
+				return models.Header{}, decodeErr(err)
+			}
+		}
+	}
+
+	if err := root.CheckError(); err != nil {
+		return models.Header{}, err
+	}
+
+	return root.GetResponse().Header, nil
+}
@@ -0,0 +1,309 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+const (
+	envelopeHead = `<?xml version="1.0" encoding="utf-8"?><soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">` +
+		`<soap:Body><MakeRequestResponse xmlns="http://www.goglobal.travel/"><MakeRequestResult>`
+	envelopeTail = `</MakeRequestResult></MakeRequestResponse></soap:Body></soap:Envelope>`
+)
+
+func jsonSearchPayload(hotels int) string {
+	items := make([]string, 0, hotels)
+	for i := 1; i <= hotels; i++ {
+		items = append(items, fmt.Sprintf(`{"HotelCode":%d,"HotelName":"Hôtel \"Étoile\" & Spa №%d",`+
+			`"Offers":[{"HotelSearchCode":"%d/1","TotalPrice":%d.50,"Currency":"EUR"}]}`, i, i, i, 100+i))
+	}
+
+	return `{"Header":{"OperationType":"Response","Stats":{"HotelQty":` + fmt.Sprint(hotels) + `}},"Hotels":[` +
+		strings.Join(items, ",") + `]}`
+}
+
+func xmlSearchPayload(hotels int) string {
+	b := strings.Builder{}
+	b.WriteString(`<Root><Header><OperationType>Response</OperationType></Header><Main>`)
+	for i := 1; i <= hotels; i++ {
+		fmt.Fprintf(&b, `<Hotel><HotelCode>%d</HotelCode><HotelName>Hôtel &quot;Étoile&quot; &amp; Spa №%d</HotelName>`+
+			`<Offers><Offer><HotelSearchCode>%d/1</HotelSearchCode><TotalPrice>%d.50</TotalPrice><Currency>EUR</Currency></Offer></Offers></Hotel>`,
+			i, i, i, 100+i)
+	}
+	b.WriteString(`</Main></Root>`)
+
+	return b.String()
+}
+
+func streamService(httpClient HttpClient, opts ...Option) GoGlobalService {
+	return NewGoGlobalService("http://localhost", httpClient, opts...)
+}
+
+func TestSearchStreamMatchesSearch(t *testing.T) {
+	tests := map[string]string{
+		"json":        envelopeHead + html.EscapeString(jsonSearchPayload(2000)) + envelopeTail,
+		"xml":         envelopeHead + html.EscapeString(xmlSearchPayload(2000)) + envelopeTail,
+		"json cdata":  envelopeHead + `<![CDATA[` + jsonSearchPayload(2000) + `]]>` + envelopeTail,
+		"xml numeric": envelopeHead + strings.ReplaceAll(html.EscapeString(xmlSearchPayload(50)), "&lt;", "&#60;") + envelopeTail,
+	}
+
+	for name, envelope := range tests {
+		t.Run(name, func(t *testing.T) {
+			service := streamService(&fakeHttpClient{status: http.StatusOK, body: envelope})
+
+			want, err := service.Search(context.Background(), Credentials{}, models.HotelSearchRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []models.HotelSearchResponseItem
+			err = service.SearchStream(context.Background(), Credentials{}, models.HotelSearchRequest{}, func(hotel *models.HotelSearchResponseItem) error {
+				got = append(got, *hotel)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) == 0 || !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %d hotels, Search returned %d", len(got), len(want))
+			}
+			if got[0].HotelName != `Hôtel "Étoile" & Spa №1` {
+				t.Errorf("got hotel name %q", got[0].HotelName)
+			}
+		})
+	}
+}
+
+// pipeHttpClient returns response which body is written by the test
+type pipeHttpClient struct {
+	body io.Reader
+}
+
+func (c *pipeHttpClient) Send(*http.Request) ([]byte, int, error) {
+	return nil, 0, errors.New("Send must not be used by SearchStream")
+}
+
+func (c *pipeHttpClient) Do(*http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(c.body)}, nil
+}
+
+func TestSearchStreamDecodesBeforeResponseEnds(t *testing.T) {
+	payload := html.EscapeString(jsonSearchPayload(2))
+	split := strings.Index(payload, "},{") + 2
+
+	r, w := io.Pipe()
+	service := streamService(&pipeHttpClient{body: r})
+
+	first := make(chan int)
+	done := make(chan error)
+	go func() {
+		done <- service.SearchStream(context.Background(), Credentials{}, models.HotelSearchRequest{}, func(hotel *models.HotelSearchResponseItem) error {
+			if hotel.HotelCode == 1 {
+				first <- hotel.HotelCode
+			}
+			return nil
+		})
+	}()
+
+	go func() {
+		_, _ = io.WriteString(w, envelopeHead+payload[:split])
+	}()
+
+	select {
+	case <-first:
+	case err := <-done:
+		t.Fatalf("SearchStream returned %v before the first hotel", err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("first hotel was not decoded before the rest of the response")
+	}
+
+	_, _ = io.WriteString(w, payload[split:]+envelopeTail)
+	_ = w.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchStreamErrors(t *testing.T) {
+	supplierError := envelopeHead + html.EscapeString(`{"Header":{"OperationType":"Error"},"Main":{"Error":{"Code":1,"Message":"Login failed"}}}`) + envelopeTail
+
+	tests := []struct {
+		name   string
+		client *fakeHttpClient
+		check  func(error) bool
+	}{
+		{
+			name:   "soap fault",
+			client: &fakeHttpClient{status: http.StatusInternalServerError, body: soapFaultEnvelope("soap:Client")},
+			check: func(err error) bool {
+				var fault *models.SoapFault
+				return errors.As(err, &fault)
+			},
+		},
+		{
+			name:   "http status",
+			client: &fakeHttpClient{status: http.StatusBadGateway, body: "bad gateway"},
+			check: func(err error) bool {
+				var statusErr *StatusError
+				return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadGateway
+			},
+		},
+		{
+			name:   "supplier error",
+			client: &fakeHttpClient{status: http.StatusOK, body: supplierError},
+			check: func(err error) bool {
+				return errors.Is(err, models.ErrAuthentication)
+			},
+		},
+		{
+			name:   "truncated response",
+			client: &fakeHttpClient{status: http.StatusOK, body: envelopeHead + html.EscapeString(jsonSearchPayload(3))[:100]},
+			check: func(err error) bool {
+				return errors.Is(err, io.ErrUnexpectedEOF)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := streamService(test.client).SearchStream(context.Background(), Credentials{}, models.HotelSearchRequest{},
+				func(*models.HotelSearchResponseItem) error { return nil })
+			if !test.check(err) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestSearchStreamShortCircuitInterceptor(t *testing.T) {
+	envelope := envelopeHead + html.EscapeString(jsonSearchPayload(3)) + envelopeTail
+	cached := func(context.Context, *Call, Invoker) ([]byte, int, error) {
+		return []byte(envelope), 0, nil
+	}
+	service := streamService(&pipeHttpClient{}, WithInterceptors(cached))
+
+	hotels := 0
+	err := service.SearchStream(context.Background(), Credentials{}, models.HotelSearchRequest{}, func(*models.HotelSearchResponseItem) error {
+		hotels++
+		return nil
+	})
+	if err != nil || hotels != 3 {
+		t.Fatalf("got %d hotels, error %v", hotels, err)
+	}
+}
+
+func TestDecodeEnvelopeByteByByte(t *testing.T) {
+	payload := `{"Name":"a < b & c > d","Quote":"it's \"x\"","Snowman":"\u2603 ☃"}`
+	tests := map[string]string{
+		"escaped": html.EscapeString(payload),
+		"numeric": strings.NewReplacer("<", "&#60;", ">", "&#x3E;", "&", "&#x26;", "☃", "&#9731;").Replace(payload),
+		"cdata":   `<![CDATA[` + payload + `]]>`,
+		"mixed":   html.EscapeString(payload[:20]) + `<![CDATA[` + payload[20:40] + `]]>` + html.EscapeString(payload[40:]),
+		"bracket": `<![CDATA[` + payload + `]]]]>` + html.EscapeString("]"),
+	}
+
+	for name, result := range tests {
+		t.Run(name, func(t *testing.T) {
+			want := payload
+			if name == "bracket" {
+				want = payload + "]]]"
+			}
+			envelope := envelopeHead + result + envelopeTail
+			for _, r := range []io.Reader{strings.NewReader(envelope), iotest.OneByteReader(strings.NewReader(envelope))} {
+				var got []byte
+				err := decodeEnvelope(searchRequest, r, func(text io.Reader) error {
+					var err error
+					got, err = io.ReadAll(iotest.OneByteReader(text))
+					return err
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("got %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+// countingRetryPolicy retries every failure once without delay and counts the calls
+type countingRetryPolicy struct {
+	backoffs  int
+	successes int
+}
+
+func (p *countingRetryPolicy) Backoff(_ string, attempt int, _ int, _ error) (time.Duration, bool) {
+	p.backoffs++
+	return 0, attempt < 2
+}
+
+func (p *countingRetryPolicy) OnSuccess(string) {
+	p.successes++
+}
+
+// brokenBodyClient answers with a response which body fails after body
+type brokenBodyClient struct {
+	body string
+	err  error
+}
+
+func (c *brokenBodyClient) Send(*http.Request) ([]byte, int, error) {
+	return nil, 0, errors.New("not used")
+}
+
+func (c *brokenBodyClient) Do(*http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(io.MultiReader(strings.NewReader(c.body), iotest.ErrReader(c.err))),
+	}, nil
+}
+
+func TestSearchStreamRetrySuccess(t *testing.T) {
+	reset := errors.New("connection reset by peer")
+	payload := html.EscapeString(jsonSearchPayload(3))
+	tests := []struct {
+		name       string
+		httpClient HttpClient
+		failed     bool
+	}{
+		{name: "decoded", httpClient: &fakeHttpClient{status: http.StatusOK, body: envelopeHead + payload + envelopeTail}},
+		{name: "read error while decoding", httpClient: &brokenBodyClient{body: envelopeHead + payload[:len(payload)/2], err: reset}, failed: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy := &countingRetryPolicy{}
+			service := streamService(test.httpClient, WithRetryPolicy(policy))
+
+			err := service.SearchStream(context.Background(), Credentials{}, models.HotelSearchRequest{}, func(*models.HotelSearchResponseItem) error {
+				return nil
+			})
+
+			if test.failed {
+				var transportErr *TransportError
+				if !errors.As(err, &transportErr) || !errors.Is(err, reset) {
+					t.Errorf("got error %v, want TransportError of %v", err, reset)
+				}
+				if policy.successes != 0 || policy.backoffs != 0 {
+					t.Errorf("got %d successes and %d retries, want none", policy.successes, policy.backoffs)
+				}
+				return
+			}
+			if err != nil || policy.successes != 1 {
+				t.Errorf("got error %v and %d successes, want 1 success", err, policy.successes)
+			}
+		})
+	}
+}
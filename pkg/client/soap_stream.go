@@ -0,0 +1,399 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+const (
+	//longest entity kept in MakeRequestResult text, eg. &#x10FFFF;
+	maxEntityLength = 12
+	cdataStart      = "<![CDATA["
+	cdataEnd        = "]]>"
+
+	soapEnvelopeNamespace = "http://schemas.xmlsoap.org/soap/envelope/"
+)
+
+var errNoRequestResult = errors.New("MakeRequestResult not found")
+
+// doStreamRequest is doRequest passing MakeRequestResult payload to decode as it arrives, instead of reading
+// the whole response first. Interceptors see the call, status and error, but not the streamed response body.
+// When an interceptor returns its own response without calling next, that response is decoded instead.
+// Returns the number of response bytes read
+func (c *goGlobalService) doStreamRequest(
+	ctx context.Context,
+	credentials Credentials,
+	operation goGlobalRequest,
+	request any,
+	decode func(io.Reader) error,
+) (int, error) {
+	payload, err := buildEnvelope(credentials, operation, request)
+	if err != nil {
+		return 0, err
+	}
+
+	streamed, size := false, 0
+	invoker := chainInterceptors(c.interceptors, func(ctx context.Context, call *Call) ([]byte, int, error) {
+		streamed = true
+		body, n, status, err := c.sendStream(ctx, credentials, operation, restorePassword(call.Envelope, credentials.Password), decode)
+		size = n
+
+		return body, status, err
+	})
+
+	body, status, err := invoker(ctx, &Call{
+		Operation:   string(operation),
+		RequestType: requestTypes[operation],
+		Credentials: credentials.Redacted(),
+		Envelope:    payload,
+	})
+	if err != nil {
+		return size, err
+	}
+	if status != 0 && (status < http.StatusOK || status >= http.StatusMultipleChoices) {
+		return len(body), failedResponseError(operation, status, body)
+	}
+	if streamed {
+		return size, nil
+	}
+
+	return len(body), decodeEnvelope(operation, bytes.NewReader(body), decode)
+}
+
+// sendStream posts the envelope and decodes the response while it's read. Only attempts failed before
+// the response was passed to decode are repeated, then the body of the failed response is returned as in send
+func (c *goGlobalService) sendStream(
+	ctx context.Context,
+	credentials Credentials,
+	operation goGlobalRequest,
+	payload []byte,
+	decode func(io.Reader) error,
+) ([]byte, int, int, error) {
+	for attempt := 1; ; attempt++ {
+		body, size, status, decoded, err := c.sendStreamAttempt(ctx, credentials, operation, payload, decode)
+		if c.retryPolicy == nil {
+			return body, size, status, err
+		}
+		if err == nil && (decoded || !isRetryableFailure(status, nil)) {
+			c.retryPolicy.OnSuccess(string(operation))
+			return body, size, status, nil
+		}
+		if decoded {
+			//the response was already passed to decode, it can't be repeated
+			return body, size, status, err
+		}
+		if !idempotentRequests[operation] && !isNotSentError(err) {
+			return body, size, status, err
+		}
+
+		backoff, retry := c.retryPolicy.Backoff(string(operation), attempt, status, err)
+		if !retry {
+			return body, size, status, err
+		}
+		if sleepErr := sleepContext(ctx, backoff); sleepErr != nil {
+			if err == nil {
+				err = sleepErr
+			}
+			return body, size, status, err
+		}
+	}
+}
+
+// sendStreamAttempt returns the body of non 2xx responses, 2xx responses are passed to decode and decoded is set.
+// Only failures to read the response count for the circuit breaker, errors of decode do not
+func (c *goGlobalService) sendStreamAttempt(
+	ctx context.Context,
+	credentials Credentials,
+	operation goGlobalRequest,
+	payload []byte,
+	decode func(io.Reader) error,
+) (body []byte, size int, status int, decoded bool, err error) {
+	var failure error
+	if c.circuitBreaker != nil {
+		if err = c.circuitBreaker.Allow(operation); err != nil {
+			return nil, 0, 0, false, err
+		}
+		defer func() {
+			c.circuitBreaker.Done(ctx, operation, status, failure)
+		}()
+	}
+
+	if c.rateLimiter != nil {
+		if err = c.rateLimiter.Wait(ctx, credentials.AgencyId, operation); err != nil {
+			failure = err
+			return nil, 0, 0, false, err
+		}
+	}
+
+	req, err := c.newSoapRequest(ctx, credentials, operation, payload)
+	if err != nil {
+		failure = err
+		return nil, 0, 0, false, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		failure = &TransportError{Operation: string(operation), Err: err}
+		return nil, 0, 0, false, failure
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	status = resp.StatusCode
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			failure = &TransportError{Operation: string(operation), Err: fmt.Errorf("read response: %w", err)}
+			return nil, len(body), status, false, failure
+		}
+		if fault := clientFault(operation, status, body); fault != nil {
+			failure = fault
+			return body, len(body), status, false, fault
+		}
+		return body, len(body), status, false, nil
+	}
+
+	reader := &responseReader{r: resp.Body}
+	err = decodeEnvelope(operation, reader, decode)
+	if reader.err != nil && reader.err != io.EOF {
+		failure = &TransportError{Operation: string(operation), Err: fmt.Errorf("read response: %w", reader.err)}
+		if err == nil || errors.Is(err, reader.err) {
+			err = failure
+		}
+	}
+
+	return nil, reader.n, status, true, err
+}
+
+// failedResponseError returns soap fault of non 2xx response or StatusError
+func failedResponseError(operation goGlobalRequest, status int, body []byte) error {
+	response := models.EnvelopeResponse{}
+	if err := unmarshalXml(string(operation), body, &response); err == nil && response.Body.Fault != nil {
+		return response.Body.Fault
+	}
+
+	return newStatusError(string(operation), status, body)
+}
+
+// responseReader counts read bytes and keeps the read error
+type responseReader struct {
+	r   io.Reader
+	n   int
+	err error
+}
+
+func (r *responseReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	if err != nil {
+		r.err = err
+	}
+
+	return n, err
+}
+
+// decodeEnvelope walks SOAP envelope with xml.Decoder up to MakeRequestResult and passes its unescaped text
+// to decode as it's read. Soap faults are returned as *models.SoapFault
+func decodeEnvelope(operation goGlobalRequest, r io.Reader, decode func(io.Reader) error) error {
+	//xml.Decoder does not buffer io.ByteReader, so once it returns the start element
+	//the reader is positioned right at the text of the element
+	br := bufio.NewReader(newXmlSanitizer(r))
+	decoder := xml.NewDecoder(br)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return newDecodeError(string(operation), FormatXml, nil, decoder.InputOffset(), errNoRequestResult)
+		}
+		if err != nil {
+			return newDecodeError(string(operation), FormatXml, nil, decoder.InputOffset(), err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch {
+		case start.Name.Local == "Fault" && start.Name.Space == soapEnvelopeNamespace:
+			fault := &models.SoapFault{}
+			if err = decoder.DecodeElement(fault, &start); err != nil {
+				return newDecodeError(string(operation), FormatXml, nil, decoder.InputOffset(), err)
+			}
+			return fault
+		case start.Name.Local == "MakeRequestResult":
+			return decode(&soapTextReader{r: br})
+		}
+	}
+}
+
+// soapTextReader reads text content of an element from r, resolving entities and CDATA sections,
+// up to the next tag. The payload of MakeRequestResult is escaped xml or json, so it has no tags of its own
+type soapTextReader struct {
+	r *bufio.Reader
+	//inside CDATA section
+	cdata bool
+	//resolved entity not returned yet
+	pending []byte
+	entity  [utf8.UTFMax]byte
+	done    bool
+}
+
+func (t *soapTextReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(t.pending) > 0 {
+			c := copy(p[n:], t.pending)
+			t.pending = t.pending[c:]
+			n += c
+			continue
+		}
+		if t.done {
+			break
+		}
+		//return what is decoded instead of waiting for more data
+		if n > 0 && t.r.Buffered() == 0 {
+			break
+		}
+		if err := t.next(p, &n); err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+	}
+	if n == 0 && t.done {
+		return 0, io.EOF
+	}
+
+	return n, nil
+}
+
+// next copies buffered text to p[*n:] up to the next entity, CDATA boundary or tag and handles them
+func (t *soapTextReader) next(p []byte, n *int) error {
+	if t.r.Buffered() == 0 {
+		if _, err := t.r.Peek(1); err != nil {
+			return unexpectedEOF(err)
+		}
+	}
+	chunk, _ := t.r.Peek(t.r.Buffered())
+
+	if t.cdata {
+		end := bytes.Index(chunk, []byte(cdataEnd))
+		if end == 0 {
+			t.cdata = false
+			_, _ = t.r.Discard(len(cdataEnd))
+			return nil
+		}
+		if end < 0 {
+			//the end marker may be split by the buffer end
+			end = len(chunk) - (len(cdataEnd) - 1)
+			if end <= 0 {
+				return t.fill(len(cdataEnd))
+			}
+		}
+		c := copy(p[*n:], chunk[:end])
+		*n += c
+		_, _ = t.r.Discard(c)
+		return nil
+	}
+
+	if special := bytes.IndexAny(chunk, "&<"); special != 0 {
+		if special > 0 {
+			chunk = chunk[:special]
+		}
+		c := copy(p[*n:], chunk)
+		*n += c
+		_, _ = t.r.Discard(c)
+		return nil
+	}
+
+	if chunk[0] == '<' {
+		switch {
+		case bytes.HasPrefix(chunk, []byte(cdataStart)):
+			t.cdata = true
+			_, _ = t.r.Discard(len(cdataStart))
+		case len(chunk) < len(cdataStart) && bytes.HasPrefix([]byte(cdataStart), chunk):
+			return t.fill(len(chunk) + 1)
+		default:
+			//closing tag of the element
+			t.done = true
+		}
+		return nil
+	}
+
+	head := chunk
+	if len(head) > maxEntityLength {
+		head = head[:maxEntityLength]
+	}
+	end := bytes.IndexByte(head, ';')
+	if end < 0 {
+		if len(head) < maxEntityLength {
+			return t.fill(len(head) + 1)
+		}
+		return fmt.Errorf("invalid entity %q", head)
+	}
+	r, ok := resolveEntity(string(head[1:end]))
+	if !ok {
+		return fmt.Errorf("invalid entity %q", head[:end+1])
+	}
+	_, _ = t.r.Discard(end + 1)
+	t.pending = t.entity[:utf8.EncodeRune(t.entity[:], r)]
+
+	return nil
+}
+
+// fill makes sure at least size bytes are buffered unless the reader ends
+func (t *soapTextReader) fill(size int) error {
+	if _, err := t.r.Peek(size); err != nil {
+		return unexpectedEOF(err)
+	}
+
+	return nil
+}
+
+func resolveEntity(name string) (rune, bool) {
+	switch name {
+	case "lt":
+		return '<', true
+	case "gt":
+		return '>', true
+	case "amp":
+		return '&', true
+	case "quot":
+		return '"', true
+	case "apos":
+		return '\'', true
+	}
+	if len(name) < 2 || name[0] != '#' {
+		return 0, false
+	}
+
+	base, digits := 10, name[1:]
+	if digits[0] == 'x' {
+		base, digits = 16, digits[1:]
+	}
+	value, err := strconv.ParseUint(digits, base, 32)
+	if err != nil || !utf8.ValidRune(rune(value)) {
+		return 0, false
+	}
+
+	return rune(value), true
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+const (
+	AttributeOperation       = "goglobal.operation"
+	AttributeRequestType     = "goglobal.request_type"
+	AttributeAgencyId        = "goglobal.agency_id"
+	AttributeHotelSearchCode = "goglobal.hotel_search_code"
+	AttributeGoBookingCode   = "goglobal.booking_code"
+	AttributeErrorCode       = "goglobal.error_code"
+)
+
+// Tracer starts spans around supplier operations. It is small enough to be implemented by an adapter
+// over OpenTelemetry or any other tracing library
+type Tracer interface {
+	// Start starts a span and returns the context carrying it
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type Span interface {
+	// SetAttribute sets string, int64 or int attribute
+	SetAttribute(key string, value any)
+	// RecordError marks the span as failed
+	RecordError(err error)
+	End()
+}
+
+// WithTracer enables a span for each service method call. SearchMany and SearchCities get a parent span
+// with a child span for each request they issue
+func WithTracer(tracer Tracer) Option {
+	return func(s *goGlobalService) {
+		s.tracer = tracer
+	}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, any) {}
+func (noopSpan) RecordError(error)        {}
+func (noopSpan) End()                     {}
+
+func (c *goGlobalService) startSpan(
+	ctx context.Context,
+	operation string,
+	credentials Credentials,
+	request any,
+) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	ctx, span := c.tracer.Start(ctx, operation)
+	span.SetAttribute(AttributeOperation, operation)
+	if requestType, ok := requestTypes[goGlobalRequest(operation)]; ok {
+		span.SetAttribute(AttributeRequestType, requestType)
+	}
+	span.SetAttribute(AttributeAgencyId, credentials.AgencyId)
+	setCodeAttributes(span, request)
+
+	return ctx, span
+}
+
+func endSpan(span Span, response any, err error) {
+	setCodeAttributes(span, response)
+	if err != nil {
+		span.SetAttribute(AttributeErrorCode, errorCode(err))
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// setCodeAttributes sets HotelSearchCode and GoBookingCode of the request or response when present
+func setCodeAttributes(span Span, v any) {
+	hotelSearchCode, goBookingCode := "", ""
+	switch r := v.(type) {
+	case models.BookValuationRequest:
+		hotelSearchCode = r.HotelSearchCode
+	case models.BookingInsertRequest:
+		hotelSearchCode = r.HotelSearchCode
+	case models.BookingInsertResponse:
+		goBookingCode = r.GoBookingCode
+	case models.BookingStatusRequest:
+		goBookingCode = r.GoBookingCode
+	case models.BookingSearchRequest:
+		goBookingCode = r.GoBookingCode
+	case models.AdvBookingSearchRequest:
+		hotelSearchCode = r.HotelSearchCode
+	case models.BookingCancelRequest:
+		goBookingCode = r.GoBookingCode
+	case models.VoucherDetailsRequest:
+		goBookingCode = r.GoBookingCode
+	case models.BookingInfoForAmendmentRequest:
+		goBookingCode = r.GoBookingCode
+	case models.HotelInfoRequest:
+		hotelSearchCode = r.HotelSearchCode
+	case models.PriceBreakdownRequest:
+		hotelSearchCode = r.HotelSearchCode
+	}
+
+	if hotelSearchCode != "" {
+		span.SetAttribute(AttributeHotelSearchCode, hotelSearchCode)
+	}
+	if goBookingCode != "" {
+		span.SetAttribute(AttributeGoBookingCode, goBookingCode)
+	}
+}
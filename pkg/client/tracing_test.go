@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+type spanContextKey struct{}
+
+// recordingTracer keeps every started span, parent of a span is the one carried by the start context
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	name   string
+	parent *recordingSpan
+
+	mu         sync.Mutex
+	attributes map[string]any
+	errs       []error
+	ended      bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(spanContextKey{}).(*recordingSpan)
+	span := &recordingSpan{name: name, parent: parent, attributes: map[string]any{}}
+
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+func (s *recordingSpan) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes[key] = value
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs = append(s.errs, err)
+}
+
+func (s *recordingSpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended = true
+}
+
+func TestTracerSpan(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		errorCode any
+	}{
+		{name: "success", status: http.StatusOK, body: bookingStatusEnvelope()},
+		{name: "failure", status: http.StatusBadGateway, body: "bad gateway", errorCode: "http_502"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracer := &recordingTracer{}
+			service := NewGoGlobalService("http://localhost", &fakeHttpClient{status: test.status, body: test.body}, WithTracer(tracer))
+			_, err := service.BookingStatus(context.Background(), Credentials{AgencyId: 7}, models.BookingStatusRequest{GoBookingCode: "123"})
+
+			if len(tracer.spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(tracer.spans))
+			}
+			span := tracer.spans[0]
+			if span.name != string(bookingStatus) || !span.ended || span.parent != nil {
+				t.Errorf("got span %s, ended %v, parent %v", span.name, span.ended, span.parent)
+			}
+
+			want := map[string]any{
+				AttributeOperation:     string(bookingStatus),
+				AttributeRequestType:   requestTypes[bookingStatus],
+				AttributeAgencyId:      int64(7),
+				AttributeGoBookingCode: "123",
+			}
+			if test.errorCode != nil {
+				want[AttributeErrorCode] = test.errorCode
+			}
+			if len(span.attributes) != len(want) {
+				t.Errorf("got attributes %v, want %v", span.attributes, want)
+			}
+			for key, value := range want {
+				if span.attributes[key] != value {
+					t.Errorf("attribute %s: got %v, want %v", key, span.attributes[key], value)
+				}
+			}
+			if (err != nil) != (len(span.errs) == 1) {
+				t.Errorf("call error %v, recorded %v", err, span.errs)
+			}
+		})
+	}
+}
+
+func TestTracerParentSpans(t *testing.T) {
+	search := func(operation string) func(GoGlobalService) error {
+		return func(service GoGlobalService) error {
+			if operation == searchManyOperation {
+				_, _, err := service.SearchMany(context.Background(), Credentials{},
+					models.HotelSearchRequest{Hotels: models.SearchHotels{HotelId: []int64{1, 2, 3, 4, 5}}},
+					SearchManyConfig{ChunkSize: 2},
+				)
+				return err
+			}
+			_, err := service.SearchCities(context.Background(), Credentials{},
+				models.HotelSearchRequest{CityCode: []int64{1, 2, 3}},
+				SearchCitiesConfig{},
+			)
+			return err
+		}
+	}
+	ok := envelopeHead + html.EscapeString(jsonSearchPayload(1)) + envelopeTail
+
+	tests := []struct {
+		operation string
+		status    int
+		body      string
+		failed    bool
+	}{
+		{operation: searchManyOperation, status: http.StatusOK, body: ok},
+		{operation: searchManyOperation, status: http.StatusBadGateway, body: "bad gateway", failed: true},
+		{operation: searchCitiesOperation, status: http.StatusOK, body: ok},
+		{operation: searchCitiesOperation, status: http.StatusBadGateway, body: "bad gateway", failed: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.operation, func(t *testing.T) {
+			tracer := &recordingTracer{}
+			service := NewGoGlobalService("http://localhost", &fakeHttpClient{status: test.status, body: test.body}, WithTracer(tracer))
+			err := search(test.operation)(service)
+			if (err != nil) != test.failed {
+				t.Fatalf("got error %v, want failure %v", err, test.failed)
+			}
+
+			if len(tracer.spans) != 4 {
+				t.Fatalf("got %d spans, want a parent and 3 requests", len(tracer.spans))
+			}
+			parent := tracer.spans[0]
+			if parent.name != test.operation || parent.parent != nil || !parent.ended {
+				t.Errorf("got parent %s, its parent %v, ended %v", parent.name, parent.parent, parent.ended)
+			}
+			if (len(parent.errs) == 1) != test.failed {
+				t.Errorf("parent recorded %v", parent.errs)
+			}
+			for _, span := range tracer.spans[1:] {
+				if span.name != string(searchRequest) || span.parent != parent || !span.ended {
+					t.Errorf("got child %s, parent %v, ended %v", span.name, span.parent, span.ended)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"bytes"
+	"io"
+)
+
+const (
+	sanitizerBufferSize = 32 * 1024
+	//longer references, e.g. with many leading zeros, are passed to the decoder as they are
+	maxCharRefLength = 16
+)
+
+// xmlSanitizer removes character references to characters illegal in XML 1.0, e.g. &#x0; or &#1;,
+// which the supplier sometimes puts in free text and which fail the whole decoding.
+// Valid references, e.g. &#x41;, are kept for the decoder
+type xmlSanitizer struct {
+	r   io.Reader
+	buf []byte
+	//buf[start:end] is read but not returned yet
+	start int
+	end   int
+	err   error
+}
+
+func newXmlSanitizer(r io.Reader) *xmlSanitizer {
+	return &xmlSanitizer{
+		r:   r,
+		buf: make([]byte, sanitizerBufferSize),
+	}
+}
+
+func (s *xmlSanitizer) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		if n := s.process(p); n > 0 {
+			return n, nil
+		}
+		if s.err != nil {
+			return 0, s.err
+		}
+		s.fill()
+	}
+}
+
+// process copies sanitized buffered data to p, a reference split by the buffer end is left until more data is read
+func (s *xmlSanitizer) process(p []byte) int {
+	w := 0
+	i := s.start
+	for i < s.end && w < len(p) {
+		if s.buf[i] != '&' {
+			chunk := s.buf[i:s.end]
+			if amp := bytes.IndexByte(chunk, '&'); amp >= 0 {
+				chunk = chunk[:amp]
+			}
+			n := copy(p[w:], chunk)
+			w += n
+			i += n
+			continue
+		}
+
+		length, illegal, needMore := charRef(s.buf[i:s.end])
+		if needMore && s.err == nil {
+			break
+		}
+		if illegal {
+			i += length
+			continue
+		}
+		p[w] = '&'
+		w++
+		i++
+	}
+	s.start = i
+
+	return w
+}
+
+func (s *xmlSanitizer) fill() {
+	if s.start > 0 {
+		s.end = copy(s.buf, s.buf[s.start:s.end])
+		s.start = 0
+	}
+
+	n, err := s.r.Read(s.buf[s.end:])
+	s.end += n
+	s.err = err
+}
+
+// charRef checks whether b starts with a character reference, returns its length and whether the character is
+// illegal in XML 1.0. needMore is set when b ends before the reference could be recognized
+func charRef(b []byte) (length int, illegal bool, needMore bool) {
+	if len(b) < 3 {
+		return 0, false, len(b) < 2 || b[1] == '#'
+	}
+	if b[1] != '#' {
+		return 0, false, false
+	}
+
+	base, i := 10, 2
+	if b[2] == 'x' {
+		base, i = 16, 3
+	}
+
+	value, digits := 0, 0
+	for ; i < len(b) && i < maxCharRefLength; i++ {
+		if b[i] == ';' {
+			if digits == 0 {
+				return 0, false, false
+			}
+			return i + 1, !isXmlChar(value), false
+		}
+
+		d := digitValue(b[i], base)
+		if d < 0 {
+			return 0, false, false
+		}
+		if value <= 0x10FFFF {
+			value = value*base + d
+		}
+		digits++
+	}
+
+	return 0, false, i < maxCharRefLength
+}
+
+func digitValue(c byte, base int) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case base == 16 && c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case base == 16 && c >= 'A' && c <= 'F':
+		return int(c-'A') + 10
+	}
+
+	return -1
+}
+
+// isXmlChar reports whether the code point is allowed by the Char production of XML 1.0
+func isXmlChar(r int) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
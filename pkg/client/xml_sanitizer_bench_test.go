@@ -0,0 +1,90 @@
+package client
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+// benchmarkEnvelope returns ~1MB search response envelope. The payload is escaped first and the illegal
+// references are put into the escaped text afterwards, as the supplier sends them, so the envelope
+// can't be decoded unless they are stripped
+func benchmarkEnvelope() []byte {
+	hotel := `<Hotel><HotelName>Hotel &#x41;rt{illegal}</HotelName><HotelCode>1</HotelCode>` +
+		`<Offers><Offer><HotelSearchCode>123/456/789</HotelSearchCode><TotalPrice>100.5</TotalPrice>` +
+		`<Remark>Free text remark{illegal}</Remark></Offer></Offers></Hotel>`
+	payload := `<Root><Header><OperationType>Response</OperationType></Header><Main>` +
+		strings.Repeat(hotel, 4000) + `</Main></Root>`
+	escaped := strings.ReplaceAll(html.EscapeString(payload), "{illegal}", "&#x0;&#x1F;")
+
+	return []byte(`<?xml version="1.0" encoding="utf-8"?>` +
+		`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+		`<MakeRequestResponse xmlns="http://www.goglobal.travel/"><MakeRequestResult>` +
+		escaped +
+		`</MakeRequestResult></MakeRequestResponse></soap:Body></soap:Envelope>`)
+}
+
+// BenchmarkUnmarshalEnvelopeRegexp is the previous approach: a regexp pass over the body converted to string
+func BenchmarkUnmarshalEnvelopeRegexp(b *testing.B) {
+	body := benchmarkEnvelope()
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		re := regexp.MustCompile(`&#x[\da-fA-F]+;`)
+		cleaned := []byte(re.ReplaceAllString(string(body), ""))
+		response := models.EnvelopeResponse{}
+		if err := xml.NewDecoder(bytes.NewReader(cleaned)).Decode(&response); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalEnvelopeSanitizer(b *testing.B) {
+	body := benchmarkEnvelope()
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		response := models.EnvelopeResponse{}
+		if err := unmarshalXml("bench", body, &response); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSanitizeRegexp(b *testing.B) {
+	body := benchmarkEnvelope()
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		re := regexp.MustCompile(`&#x[\da-fA-F]+;`)
+		_ = []byte(re.ReplaceAllString(string(body), ""))
+	}
+}
+
+func BenchmarkSanitizeReader(b *testing.B) {
+	body := benchmarkEnvelope()
+	buf := make([]byte, 4096)
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s := newXmlSanitizer(bytes.NewReader(body))
+		for {
+			if _, err := s.Read(buf); err != nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+func TestXmlSanitizer(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"valid hex reference", "a&#x41;b", "a&#x41;b"},
+		{"valid decimal reference", "a&#65;b", "a&#65;b"},
+		{"illegal hex reference", "a&#x0;b", "ab"},
+		{"illegal decimal reference", "a&#1;b", "ab"},
+		{"illegal upper case hex", "a&#x1F;&#X1;b", "a&#X1;b"},
+		{"out of range", "a&#x110000;b", "ab"},
+		{"allowed control characters", "&#x9;&#xA;&#13;", "&#x9;&#xA;&#13;"},
+		{"entities", "&lt;&amp;&gt;&quot;", "&lt;&amp;&gt;&quot;"},
+		{"not a reference", "a & b &#; &#xZ; &#x41", "a & b &#; &#xZ; &#x41"},
+		{"too long", "&#x00000000000000041;", "&#x00000000000000041;"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, r := range []io.Reader{strings.NewReader(test.input), iotest.OneByteReader(strings.NewReader(test.input))} {
+				got, err := io.ReadAll(newXmlSanitizer(r))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != test.want {
+					t.Errorf("got %q, want %q", got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestXmlSanitizerBufferBoundary(t *testing.T) {
+	refs := []string{"&#x0;", "&#x41;", "&#1;", "&#65;"}
+	want := map[string]string{"&#x0;": "", "&#x41;": "&#x41;", "&#1;": "", "&#65;": "&#65;"}
+
+	for _, ref := range refs {
+		//the reference starts at every position around the end of the first read buffer
+		for offset := len(ref) + 1; offset >= -1; offset-- {
+			prefix := strings.Repeat("a", sanitizerBufferSize-offset)
+			input := prefix + ref + "b"
+
+			got, err := io.ReadAll(newXmlSanitizer(strings.NewReader(input)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := prefix + want[ref] + "b"; string(got) != expected {
+				t.Errorf("%s at %d bytes before the buffer end: got %q", ref, offset, got[len(prefix)-2:])
+			}
+		}
+	}
+}
+
+func TestBenchmarkEnvelopeHasIllegalReferences(t *testing.T) {
+	body := benchmarkEnvelope()
+
+	response := models.EnvelopeResponse{}
+	if err := xml.NewDecoder(bytes.NewReader(body)).Decode(&response); err == nil {
+		t.Fatal("envelope is decoded without stripping illegal references")
+	}
+	if err := unmarshalXml("bench", body, &response); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(response.Body.MakeRequestResponse.MakeRequestResult.Data, []byte("Hotel &#x41;rt</HotelName>")) {
+		t.Error("illegal references are not stripped from the payload")
+	}
+}
@@ -0,0 +1,241 @@
+package staticdata
+
+import (
+	"math"
+	"sort"
+
+	"github.com/DmitryKolbin/go-global/pkg/client"
+	"github.com/DmitryKolbin/go-global/pkg/client/models"
+)
+
+const (
+	earthRadiusKm = 6371.0
+	//length of a degree of the great circle of DistanceKm
+	kmPerDegree = earthRadiusKm * math.Pi / 180
+
+	//DefaultGeoCellSizeKm is a reasonable grid cell for city scale queries
+	DefaultGeoCellSizeKm = 5
+)
+
+// GeoMatch is a hotel found by GeoIndex.WithinRadius
+type GeoMatch struct {
+	HotelID    int64
+	DistanceKm float64
+}
+
+type geoEntry struct {
+	hotelId   int64
+	latitude  float64
+	longitude float64
+}
+
+type geoCell struct {
+	lat int
+	lon int
+}
+
+// GeoIndex is a grid index over hotel coordinates for radius and bounding box queries.
+// Hotels without coordinates (0, 0) are not indexed. GeoIndex is immutable and safe for concurrent use
+type GeoIndex struct {
+	cellDegrees float64
+	lonCells    int
+	cells       map[geoCell][]geoEntry
+}
+
+// NewGeoIndex builds index from GetHotels output, cellSizeKm <= 0 means DefaultGeoCellSizeKm
+func NewGeoIndex(hotels []*client.Hotel, cellSizeKm float64) *GeoIndex {
+	if cellSizeKm <= 0 {
+		cellSizeKm = DefaultGeoCellSizeKm
+	}
+
+	g := &GeoIndex{
+		cellDegrees: cellSizeKm / kmPerDegree,
+		cells:       map[geoCell][]geoEntry{},
+	}
+	g.lonCells = int(math.Ceil(360 / g.cellDegrees))
+
+	for _, hotel := range hotels {
+		if hotel == nil || (hotel.Latitude == 0 && hotel.Longitude == 0) {
+			continue
+		}
+		if hotel.Latitude < -90 || hotel.Latitude > 90 || hotel.Longitude < -180 || hotel.Longitude > 180 {
+			continue
+		}
+
+		cell := g.cell(hotel.Latitude, hotel.Longitude)
+		g.cells[cell] = append(g.cells[cell], geoEntry{
+			hotelId:   hotel.HotelID,
+			latitude:  hotel.Latitude,
+			longitude: hotel.Longitude,
+		})
+	}
+
+	return g
+}
+
+// WithinRadius returns hotels within radiusKm of the point, nearest first
+func (g *GeoIndex) WithinRadius(latitude, longitude, radiusKm float64) []GeoMatch {
+	//the box is padded a bit so points at exactly radiusKm are not lost to rounding
+	angle := radiusKm / earthRadiusKm * (1 + 1e-9)
+	latDelta := angle * 180 / math.Pi
+	minLat, maxLat := latitude-latDelta, latitude+latDelta
+
+	//the widest point of the circle is not due east but where a meridian touches it
+	minLon, maxLon := -180.0, 180.0
+	if cos := math.Cos(latitude * math.Pi / 180); minLat > -90 && maxLat < 90 {
+		if sin := math.Sin(angle) / cos; sin < 1 {
+			lonDelta := math.Asin(sin) * 180 / math.Pi
+			minLon, maxLon = normalizeLongitude(longitude-lonDelta), normalizeLongitude(longitude+lonDelta)
+		}
+	}
+
+	var matches []GeoMatch
+	g.scan(minLat, minLon, maxLat, maxLon, func(entry geoEntry) {
+		if distance := DistanceKm(latitude, longitude, entry.latitude, entry.longitude); distance <= radiusKm {
+			matches = append(matches, GeoMatch{HotelID: entry.hotelId, DistanceKm: distance})
+		}
+	})
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].DistanceKm != matches[j].DistanceKm {
+			return matches[i].DistanceKm < matches[j].DistanceKm
+		}
+		return matches[i].HotelID < matches[j].HotelID
+	})
+
+	return matches
+}
+
+// InBoundingBox returns ids of hotels inside the box. The box crosses the antimeridian when minLongitude > maxLongitude
+func (g *GeoIndex) InBoundingBox(minLatitude, minLongitude, maxLatitude, maxLongitude float64) []int64 {
+	var ids []int64
+	g.scan(minLatitude, minLongitude, maxLatitude, maxLongitude, func(entry geoEntry) {
+		if entry.latitude < minLatitude || entry.latitude > maxLatitude {
+			return
+		}
+		if minLongitude <= maxLongitude {
+			if entry.longitude < minLongitude || entry.longitude > maxLongitude {
+				return
+			}
+		} else if entry.longitude < minLongitude && entry.longitude > maxLongitude {
+			return
+		}
+		ids = append(ids, entry.hotelId)
+	})
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	return ids
+}
+
+// HotelIds returns ids of the matches in the same order
+func HotelIds(matches []GeoMatch) []int64 {
+	ids := make([]int64, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.HotelID)
+	}
+
+	return ids
+}
+
+// SearchRequests copies base request for each batch of up to models.MaxSearchHotelIds hotel ids,
+// CityCode is cleared as the supplier expects either cities or hotels
+func SearchRequests(base models.HotelSearchRequest, hotelIds []int64) []models.HotelSearchRequest {
+	batches := models.SplitHotelIds(hotelIds, models.MaxSearchHotelIds)
+	requests := make([]models.HotelSearchRequest, 0, len(batches))
+	for _, batch := range batches {
+		request := base
+		request.CityCode = nil
+		request.Hotels = batch
+		requests = append(requests, request)
+	}
+
+	return requests
+}
+
+// DistanceKm returns great-circle distance between two points
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// scan calls fn for every entry in cells intersecting the box, entries still have to be checked by the caller
+func (g *GeoIndex) scan(minLat, minLon, maxLat, maxLon float64, fn func(geoEntry)) {
+	minLat, maxLat = math.Max(minLat, -90), math.Min(maxLat, 90)
+	if minLat > maxLat {
+		return
+	}
+
+	fromLat, toLat := g.cell(minLat, 0).lat, g.cell(maxLat, 0).lat
+	fromLon, toLon := g.cell(0, minLon).lon, g.cell(0, maxLon).lon
+	lonCount := toLon - fromLon + 1
+	switch {
+	case minLon <= -180 && maxLon >= 180:
+		fromLon, lonCount = 0, g.lonCells
+	case minLon > maxLon:
+		//crosses the antimeridian
+		lonCount = g.lonCells - fromLon + toLon + 1
+	}
+	if lonCount > g.lonCells {
+		lonCount = g.lonCells
+	}
+
+	//sparse data - cheaper to walk the populated cells than the grid
+	if (toLat-fromLat+1)*lonCount > len(g.cells) {
+		for cell, entries := range g.cells {
+			if cell.lat < fromLat || cell.lat > toLat || !lonCellInRange(cell.lon, fromLon, lonCount, g.lonCells) {
+				continue
+			}
+			for _, entry := range entries {
+				fn(entry)
+			}
+		}
+		return
+	}
+
+	for lat := fromLat; lat <= toLat; lat++ {
+		for i := 0; i < lonCount; i++ {
+			for _, entry := range g.cells[geoCell{lat: lat, lon: (fromLon + i) % g.lonCells}] {
+				fn(entry)
+			}
+		}
+	}
+}
+
+// cell returns the grid cell of the point, longitude 180 is put in the last column together with the ones just west of it
+func (g *GeoIndex) cell(latitude, longitude float64) geoCell {
+	lon := int(math.Floor((longitude + 180) / g.cellDegrees))
+	if lon >= g.lonCells {
+		lon = g.lonCells - 1
+	}
+	if lon < 0 {
+		lon = 0
+	}
+
+	return geoCell{
+		lat: int(math.Floor((latitude + 90) / g.cellDegrees)),
+		lon: lon,
+	}
+}
+
+func lonCellInRange(lon, from, count, total int) bool {
+	return (lon-from+total)%total < count
+}
+
+func normalizeLongitude(longitude float64) float64 {
+	for longitude > 180 {
+		longitude -= 360
+	}
+	for longitude < -180 {
+		longitude += 360
+	}
+
+	return longitude
+}
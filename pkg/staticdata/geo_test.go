@@ -0,0 +1,91 @@
+package staticdata
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DmitryKolbin/go-global/pkg/client"
+)
+
+func TestGeoIndexWholeWorld(t *testing.T) {
+	hotels := []*client.Hotel{
+		{HotelID: 1, Latitude: 48.85, Longitude: 2.35},
+		{HotelID: 2, Latitude: -33.87, Longitude: 151.21},
+		{HotelID: 3, Latitude: 64.2, Longitude: -178.5},
+		{HotelID: 4, Latitude: -17.7, Longitude: 180},
+		{HotelID: 5, Latitude: 40.71, Longitude: -74.01},
+	}
+
+	//360 / cellDegrees is a whole number for these sizes
+	for _, cellSizeKm := range []float64{DefaultGeoCellSizeKm, kmPerDegree / 2, kmPerDegree, 500} {
+		index := NewGeoIndex(hotels, cellSizeKm)
+
+		if ids := index.InBoundingBox(-90, -180, 90, 180); len(ids) != len(hotels) {
+			t.Errorf("cell %v km: InBoundingBox of the whole world returned %v", cellSizeKm, ids)
+		}
+		if matches := index.WithinRadius(10, 20, 30000); len(matches) != len(hotels) {
+			t.Errorf("cell %v km: WithinRadius of the whole world returned %v", cellSizeKm, matches)
+		}
+		if ids := index.InBoundingBox(-20, 179, -15, -179); len(ids) != 1 || ids[0] != 4 {
+			t.Errorf("cell %v km: InBoundingBox across the antimeridian returned %v", cellSizeKm, ids)
+		}
+		if matches := index.WithinRadius(-17.7, 179.9, 20); len(matches) != 1 || matches[0].HotelID != 4 {
+			t.Errorf("cell %v km: WithinRadius near the antimeridian returned %v", cellSizeKm, matches)
+		}
+	}
+}
+
+// destination returns the point at distanceKm from the start going along bearing degrees
+func destination(latitude, longitude, bearing, distanceKm float64) (float64, float64) {
+	const rad = math.Pi / 180
+	angle := distanceKm / earthRadiusKm
+	lat1, lon1, b := latitude*rad, longitude*rad, bearing*rad
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(angle) + math.Cos(lat1)*math.Sin(angle)*math.Cos(b))
+	lon2 := lon1 + math.Atan2(math.Sin(b)*math.Sin(angle)*math.Cos(lat1), math.Cos(angle)-math.Sin(lat1)*math.Sin(lat2))
+
+	return lat2 / rad, normalizeLongitude(lon2 / rad)
+}
+
+func TestGeoIndexWithinRadiusBoundary(t *testing.T) {
+	for _, latitude := range []float64{0, 1.5, 45, -60, 75, 88.5} {
+		for _, radiusKm := range []float64{1.1988, 12.5, 300} {
+			center := 37.3
+			var hotels []*client.Hotel
+			for bearing := 0; bearing < 360; bearing++ {
+				lat, lon := destination(latitude, center, float64(bearing), radiusKm)
+				hotels = append(hotels, &client.Hotel{HotelID: int64(bearing), Latitude: lat, Longitude: lon})
+			}
+			//points where the circle touches the meridians, the widest part of it, unless it covers the pole
+			if angle := radiusKm / earthRadiusKm; angle < math.Pi/2-math.Abs(latitude)*math.Pi/180 {
+				touchLat := math.Asin(math.Sin(latitude*math.Pi/180)/math.Cos(angle)) * 180 / math.Pi
+				lonDelta := math.Asin(math.Sin(angle)/math.Cos(latitude*math.Pi/180)) * 180 / math.Pi
+				hotels = append(hotels,
+					&client.Hotel{HotelID: 360, Latitude: touchLat, Longitude: center + lonDelta},
+					&client.Hotel{HotelID: 361, Latitude: touchLat, Longitude: center - lonDelta},
+				)
+			}
+
+			for _, cellSizeKm := range []float64{0.5, DefaultGeoCellSizeKm} {
+				index := NewGeoIndex(hotels, cellSizeKm)
+				for _, hotel := range hotels {
+					distance := DistanceKm(latitude, center, hotel.Latitude, hotel.Longitude)
+					if !containsHotel(index.WithinRadius(latitude, center, distance), hotel.HotelID) {
+						t.Errorf("lat %v, radius %v km, cell %v km: hotel %d at %v, %v is at the radius but not found",
+							latitude, radiusKm, cellSizeKm, hotel.HotelID, hotel.Latitude, hotel.Longitude)
+					}
+				}
+			}
+		}
+	}
+}
+
+func containsHotel(matches []GeoMatch, hotelId int64) bool {
+	for _, match := range matches {
+		if match.HotelID == hotelId {
+			return true
+		}
+	}
+
+	return false
+}
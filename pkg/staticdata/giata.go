@@ -0,0 +1,133 @@
+package staticdata
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/DmitryKolbin/go-global/pkg/client"
+)
+
+// GiataDuplicate is a group of Go Global hotels mapped to the same GIATA code
+type GiataDuplicate struct {
+	GiataCode string
+	//Sorted ascending, the first one is the canonical hotel
+	HotelIds []int64
+}
+
+// GiataMapping maps GIATA codes to Go Global hotels and back. Go Global sometimes lists the same property
+// under several HotelIDs, such hotels share a GIATA code and are reported by Duplicates.
+// GiataMapping is immutable and safe for concurrent use
+type GiataMapping struct {
+	hotelsByGiata map[string][]int64
+	giataByHotel  map[int64]string
+}
+
+// NewGiataMapping builds mapping from GetHotels output, hotels without GIATA code are skipped
+func NewGiataMapping(hotels []*client.Hotel) *GiataMapping {
+	m := &GiataMapping{
+		hotelsByGiata: map[string][]int64{},
+		giataByHotel:  map[int64]string{},
+	}
+
+	for _, hotel := range hotels {
+		if hotel == nil {
+			continue
+		}
+		code := normalizeGiataCode(hotel.GiataCode)
+		if code == "" {
+			continue
+		}
+		if _, ok := m.giataByHotel[hotel.HotelID]; ok {
+			continue
+		}
+		m.giataByHotel[hotel.HotelID] = code
+		m.hotelsByGiata[code] = append(m.hotelsByGiata[code], hotel.HotelID)
+	}
+
+	for _, ids := range m.hotelsByGiata {
+		sort.Slice(ids, func(i, j int) bool {
+			return ids[i] < ids[j]
+		})
+	}
+
+	return m
+}
+
+// HotelIds returns Go Global hotels mapped to the GIATA code, canonical first
+func (m *GiataMapping) HotelIds(giataCode string) []int64 {
+	return append([]int64(nil), m.hotelsByGiata[normalizeGiataCode(giataCode)]...)
+}
+
+// GiataCode returns GIATA code of the Go Global hotel
+func (m *GiataMapping) GiataCode(hotelId int64) (string, bool) {
+	code, ok := m.giataByHotel[hotelId]
+	return code, ok
+}
+
+// Resolve returns Go Global hotel ids for the GIATA codes, ready for HotelSearchRequest.Hotels or SearchRequests.
+// Without allDuplicates only the canonical hotel of each code is returned, otherwise all of them.
+// Codes without Go Global hotels are returned as missing
+func (m *GiataMapping) Resolve(giataCodes []string, allDuplicates bool) (hotelIds []int64, missing []string) {
+	seen := map[int64]bool{}
+	for _, giataCode := range giataCodes {
+		ids := m.hotelsByGiata[normalizeGiataCode(giataCode)]
+		if len(ids) == 0 {
+			missing = append(missing, giataCode)
+			continue
+		}
+		if !allDuplicates {
+			ids = ids[:1]
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				hotelIds = append(hotelIds, id)
+			}
+		}
+	}
+
+	return hotelIds, missing
+}
+
+// Canonical returns the hotel representing all duplicates of hotelId, hotelId itself when it has none
+// or no GIATA code
+func (m *GiataMapping) Canonical(hotelId int64) int64 {
+	code, ok := m.giataByHotel[hotelId]
+	if !ok {
+		return hotelId
+	}
+
+	return m.hotelsByGiata[code][0]
+}
+
+// IsDuplicate reports whether another Go Global hotel shares GIATA code with hotelId
+func (m *GiataMapping) IsDuplicate(hotelId int64) bool {
+	code, ok := m.giataByHotel[hotelId]
+	return ok && len(m.hotelsByGiata[code]) > 1
+}
+
+// Duplicates returns groups of Go Global hotels sharing a GIATA code, sorted by the code
+func (m *GiataMapping) Duplicates() []GiataDuplicate {
+	var res []GiataDuplicate
+	for code, ids := range m.hotelsByGiata {
+		if len(ids) > 1 {
+			res = append(res, GiataDuplicate{GiataCode: code, HotelIds: append([]int64(nil), ids...)})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].GiataCode < res[j].GiataCode
+	})
+
+	return res
+}
+
+// normalizeGiataCode trims the code, the dumps use empty and 0 values for hotels without mapping
+func normalizeGiataCode(code string) string {
+	code = strings.TrimSpace(code)
+	if code == "0" {
+		return ""
+	}
+
+	return code
+}
@@ -0,0 +1,114 @@
+package staticdata
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DmitryKolbin/go-global/pkg/client"
+)
+
+func giataHotels() []*client.Hotel {
+	return []*client.Hotel{
+		{HotelID: 30, GiataCode: "1001"},
+		{HotelID: 10, GiataCode: " 1001 "},
+		{HotelID: 20, GiataCode: "1001"},
+		{HotelID: 40, GiataCode: "2002"},
+		{HotelID: 50, GiataCode: ""},
+		{HotelID: 60, GiataCode: "0"},
+		//the first code of a repeated hotel wins
+		{HotelID: 40, GiataCode: "3003"},
+		nil,
+		{HotelID: 70, GiataCode: "0300"},
+		{HotelID: 80, GiataCode: "0300"},
+	}
+}
+
+func TestGiataMapping(t *testing.T) {
+	m := NewGiataMapping(giataHotels())
+
+	if got := m.HotelIds(" 1001"); !reflect.DeepEqual(got, []int64{10, 20, 30}) {
+		t.Errorf("HotelIds(1001): got %v, want canonical 10 first", got)
+	}
+	if got := m.HotelIds("3003"); got != nil {
+		t.Errorf("HotelIds(3003): got %v, want none", got)
+	}
+
+	//returned ids are a copy
+	m.HotelIds("1001")[0] = 99
+	if m.Canonical(20) != 10 {
+		t.Error("HotelIds result shares memory with the mapping")
+	}
+
+	codes := []struct {
+		hotelId int64
+		code    string
+		ok      bool
+	}{
+		{hotelId: 10, code: "1001", ok: true},
+		{hotelId: 40, code: "2002", ok: true},
+		{hotelId: 50},
+		{hotelId: 60},
+		{hotelId: 99},
+	}
+	for _, test := range codes {
+		if code, ok := m.GiataCode(test.hotelId); code != test.code || ok != test.ok {
+			t.Errorf("GiataCode(%d): got %q, %v, want %q, %v", test.hotelId, code, ok, test.code, test.ok)
+		}
+	}
+
+	canonical := []struct {
+		hotelId   int64
+		canonical int64
+		duplicate bool
+	}{
+		{hotelId: 10, canonical: 10, duplicate: true},
+		{hotelId: 30, canonical: 10, duplicate: true},
+		{hotelId: 40, canonical: 40},
+		{hotelId: 50, canonical: 50},
+		{hotelId: 80, canonical: 70, duplicate: true},
+		{hotelId: 99, canonical: 99},
+	}
+	for _, test := range canonical {
+		if got := m.Canonical(test.hotelId); got != test.canonical {
+			t.Errorf("Canonical(%d): got %d, want %d", test.hotelId, got, test.canonical)
+		}
+		if got := m.IsDuplicate(test.hotelId); got != test.duplicate {
+			t.Errorf("IsDuplicate(%d): got %v, want %v", test.hotelId, got, test.duplicate)
+		}
+	}
+
+	want := []GiataDuplicate{
+		{GiataCode: "0300", HotelIds: []int64{70, 80}},
+		{GiataCode: "1001", HotelIds: []int64{10, 20, 30}},
+	}
+	if got := m.Duplicates(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Duplicates: got %+v, want %+v", got, want)
+	}
+}
+
+func TestGiataMappingResolve(t *testing.T) {
+	m := NewGiataMapping(giataHotels())
+
+	tests := []struct {
+		name          string
+		codes         []string
+		allDuplicates bool
+		hotelIds      []int64
+		missing       []string
+	}{
+		{name: "canonical only", codes: []string{"2002", "1001"}, hotelIds: []int64{40, 10}},
+		{name: "all duplicates", codes: []string{"2002", "1001"}, allDuplicates: true, hotelIds: []int64{40, 10, 20, 30}},
+		{name: "repeated codes", codes: []string{"1001", " 1001", "1001"}, allDuplicates: true, hotelIds: []int64{10, 20, 30}},
+		{name: "missing codes", codes: []string{"404", "0", "", "0300"}, hotelIds: []int64{70}, missing: []string{"404", "0", ""}},
+		{name: "nothing", codes: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hotelIds, missing := m.Resolve(test.codes, test.allDuplicates)
+			if !reflect.DeepEqual(hotelIds, test.hotelIds) || !reflect.DeepEqual(missing, test.missing) {
+				t.Errorf("got %v, missing %q, want %v, missing %q", hotelIds, missing, test.hotelIds, test.missing)
+			}
+		})
+	}
+}
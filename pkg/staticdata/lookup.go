@@ -0,0 +1,420 @@
+package staticdata
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/DmitryKolbin/go-global/pkg/client"
+)
+
+const (
+	weightPrimary   = 1.0
+	weightSecondary = 0.7
+	weightTertiary  = 0.5
+
+	scoreExact        = 1.0
+	scorePrefix       = 0.9
+	scoreTypo         = 0.8
+	scoreTypoPrefix   = 0.7
+	scoreTypoPerError = 0.1
+
+	//share of query trigrams a candidate must contain to be scored, low enough for transposed letters
+	minTrigramShare = 0.3
+	//candidates scored per requested result, the ones with most query trigrams first
+	candidatesPerResult = 20
+
+	DefaultLookupLimit = 10
+)
+
+type DestinationMatch struct {
+	Destination client.Destination
+	Score       float64
+}
+
+type HotelMatch struct {
+	Hotel client.Hotel
+	Score float64
+}
+
+// Lookup is an autocomplete over destinations and hotels names. Matching ignores case and diacritics,
+// tolerates typos and accepts word prefixes. Lookup is immutable and safe for concurrent use
+type Lookup struct {
+	destinations []client.Destination
+	hotels       []client.Hotel
+
+	destinationIndex *textIndex
+	hotelIndex       *textIndex
+}
+
+func NewLookup(destinations []*client.Destination, hotels []*client.Hotel) *Lookup {
+	l := &Lookup{
+		destinations:     make([]client.Destination, 0, len(destinations)),
+		hotels:           make([]client.Hotel, 0, len(hotels)),
+		destinationIndex: newTextIndex(),
+		hotelIndex:       newTextIndex(),
+	}
+
+	for _, destination := range destinations {
+		if destination == nil {
+			continue
+		}
+		l.destinations = append(l.destinations, *destination)
+		l.destinationIndex.add(
+			textField{text: destination.City, weight: weightPrimary},
+			textField{text: destination.Country, weight: weightSecondary},
+			textField{text: destination.IsoCode, weight: weightTertiary},
+		)
+	}
+
+	for _, hotel := range hotels {
+		if hotel == nil {
+			continue
+		}
+		l.hotels = append(l.hotels, *hotel)
+		l.hotelIndex.add(
+			textField{text: hotel.Name, weight: weightPrimary},
+			textField{text: hotel.City, weight: weightSecondary},
+			textField{text: hotel.Address, weight: weightTertiary},
+		)
+	}
+
+	return l
+}
+
+// Destinations returns up to limit best matching destinations, Destination.CityId goes to HotelSearchRequest.CityCode
+func (l *Lookup) Destinations(query string, limit int) []DestinationMatch {
+	found := l.destinationIndex.search(query, limit)
+	res := make([]DestinationMatch, 0, len(found))
+	for _, f := range found {
+		res = append(res, DestinationMatch{Destination: l.destinations[f.entry], Score: f.score})
+	}
+
+	return res
+}
+
+// Hotels returns up to limit best matching hotels, Hotel.HotelID goes to HotelSearchRequest.Hotels
+func (l *Lookup) Hotels(query string, limit int) []HotelMatch {
+	found := l.hotelIndex.search(query, limit)
+	res := make([]HotelMatch, 0, len(found))
+	for _, f := range found {
+		res = append(res, HotelMatch{Hotel: l.hotels[f.entry], Score: f.score})
+	}
+
+	return res
+}
+
+// CityCodes returns CityId of the matches, ready for HotelSearchRequest.CityCode
+func CityCodes(matches []DestinationMatch) []int64 {
+	ids := make([]int64, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.Destination.CityId)
+	}
+
+	return ids
+}
+
+// MatchedHotelIds returns HotelID of the matches, ready for HotelSearchRequest.Hotels
+func MatchedHotelIds(matches []HotelMatch) []int64 {
+	ids := make([]int64, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.Hotel.HotelID)
+	}
+
+	return ids
+}
+
+type textField struct {
+	text   string
+	weight float64
+}
+
+type indexedToken struct {
+	token  string
+	weight float64
+}
+
+type textIndex struct {
+	entries [][]indexedToken
+	//number of distinct trigrams of each entry
+	sizes    []int32
+	trigrams map[string][]int32
+}
+
+type textMatch struct {
+	entry int
+	score float64
+}
+
+func newTextIndex() *textIndex {
+	return &textIndex{
+		trigrams: map[string][]int32{},
+	}
+}
+
+func (idx *textIndex) add(fields ...textField) {
+	entry := int32(len(idx.entries))
+	var tokens []indexedToken
+	seen := map[string]bool{}
+	for _, field := range fields {
+		for _, token := range tokenize(field.text) {
+			tokens = append(tokens, indexedToken{token: token, weight: field.weight})
+			for _, trigram := range trigrams(token) {
+				if !seen[trigram] {
+					seen[trigram] = true
+					idx.trigrams[trigram] = append(idx.trigrams[trigram], entry)
+				}
+			}
+		}
+	}
+	idx.entries = append(idx.entries, tokens)
+	idx.sizes = append(idx.sizes, int32(len(seen)))
+}
+
+func (idx *textIndex) search(query string, limit int) []textMatch {
+	if limit <= 0 {
+		limit = DefaultLookupLimit
+	}
+
+	queryTokens := tokenize(query)
+	if len(queryTokens) == 0 {
+		return nil
+	}
+
+	var queryTrigrams []string
+	for _, token := range queryTokens {
+		queryTrigrams = append(queryTrigrams, trigrams(token)...)
+	}
+
+	hits := map[int32]int{}
+	for _, trigram := range queryTrigrams {
+		for _, entry := range idx.trigrams[trigram] {
+			hits[entry]++
+		}
+	}
+
+	minHits := int(float64(len(queryTrigrams)) * minTrigramShare)
+	if minHits < 1 {
+		minHits = 1
+	}
+
+	//common trigrams like "hot" are found in most entries, so only the candidates sharing most trigrams
+	//with the query are scored, shorter ones first when they share as many. Entries containing a query token
+	//have all its trigrams, so exact and prefix matches come first
+	byHits := make([][]int32, len(queryTrigrams)+1)
+	for entry, count := range hits {
+		if count >= minHits {
+			byHits[count] = append(byHits[count], entry)
+		}
+	}
+
+	var matches []textMatch
+	candidates := limit * candidatesPerResult
+	for count := len(byHits) - 1; count >= minHits && candidates > 0; count-- {
+		entries := byHits[count]
+		sort.Slice(entries, func(i, j int) bool {
+			if a, b := idx.sizes[entries[i]], idx.sizes[entries[j]]; a != b {
+				return a < b
+			}
+			return entries[i] < entries[j]
+		})
+		if len(entries) > candidates {
+			entries = entries[:candidates]
+		}
+		candidates -= len(entries)
+
+		for _, entry := range entries {
+			if score := scoreEntry(queryTokens, idx.entries[entry]); score > 0 {
+				matches = append(matches, textMatch{entry: int(entry), score: score})
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].entry < matches[j].entry
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
+
+// scoreEntry returns average of the best score of each query token, 0 if any query token has no match
+func scoreEntry(queryTokens []string, entry []indexedToken) float64 {
+	total := 0.0
+	for _, q := range queryTokens {
+		best := 0.0
+		for _, t := range entry {
+			if score := scoreToken(q, t.token) * t.weight; score > best {
+				best = score
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+
+	return total / float64(len(queryTokens))
+}
+
+// scoreToken compares tokens by runes, so a typo in a non-latin name is one edit as in a latin one
+func scoreToken(query, token string) float64 {
+	if query == token {
+		return scoreExact
+	}
+	if strings.HasPrefix(token, query) {
+		return scorePrefix
+	}
+
+	q, t := []rune(query), []rune(token)
+	maxTypos := allowedTypos(len(q))
+	if maxTypos == 0 {
+		return 0
+	}
+	if d := editDistance(q, t, maxTypos); d <= maxTypos {
+		return scoreTypo - scoreTypoPerError*float64(d-1)
+	}
+	if len(t) > len(q) {
+		if d := editDistance(q, t[:len(q)], maxTypos); d <= maxTypos {
+			return scoreTypoPrefix - scoreTypoPerError*float64(d-1)
+		}
+	}
+
+	return 0
+}
+
+// allowedTypos returns max edit distance for a query token of length runes
+func allowedTypos(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	}
+
+	return 2
+}
+
+// editDistance is Damerau-Levenshtein (optimal string alignment) distance, returns max+1 when it exceeds max
+func editDistance(a, b []rune, max int) int {
+	if abs(len(a)-len(b)) > max {
+		return max + 1
+	}
+
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = minInt(cur[j], prev2[j-2]+1)
+			}
+			if cur[j] < rowMin {
+				rowMin = cur[j]
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	return prev[len(b)]
+}
+
+// tokenize lower cases text, removes diacritics and splits it into words. Text is decomposed (NFKD),
+// so accents of any script are separate combining marks and dropped
+func tokenize(text string) []string {
+	text = norm.NFKD.String(text)
+	b := strings.Builder{}
+	b.Grow(len(text))
+	for _, r := range text {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if folded, ok := foldedRunes[r]; ok {
+			b.WriteString(folded)
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteByte(' ')
+	}
+
+	return strings.Fields(b.String())
+}
+
+// trigrams of the token padded with spaces, so short tokens and word starts are indexed too
+func trigrams(token string) []string {
+	padded := []rune(" " + token + " ")
+	if len(padded) < 3 {
+		return nil
+	}
+
+	res := make([]string, 0, len(padded)-2)
+	for i := 0; i+3 <= len(padded); i++ {
+		res = append(res, string(padded[i:i+3]))
+	}
+
+	return res
+}
+
+// foldedRunes are latin letters with strokes and ligatures, NFKD does not decompose them
+var foldedRunes = func() map[rune]string {
+	groups := map[string]string{
+		"d":  "đ",
+		"h":  "ħ",
+		"i":  "ı",
+		"l":  "ł",
+		"o":  "ø",
+		"t":  "ŧ",
+		"ss": "ß",
+		"ae": "æ",
+		"oe": "œ",
+		"th": "þ",
+	}
+
+	res := map[rune]string{}
+	for folded, runes := range groups {
+		for _, r := range runes {
+			res[r] = folded
+		}
+	}
+
+	return res
+}()
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,125 @@
+package staticdata
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/DmitryKolbin/go-global/pkg/client"
+)
+
+func TestLookupTyposInNonLatinNames(t *testing.T) {
+	lookup := NewLookup([]*client.Destination{
+		{CityId: 1, City: "Москва", Country: "Россия"},
+		{CityId: 2, City: "Αθήνα", Country: "Ελλάδα"},
+		{CityId: 3, City: "Paris", Country: "France"},
+	}, nil)
+
+	tests := []struct {
+		query  string
+		cityId int64
+	}{
+		{"Москва", 1},
+		{"Мосвка", 1},
+		{"Моксва", 1},
+		{"Мосвк", 1},
+		{"Αθηνα", 2},
+		{"Αθινα", 2},
+		{"Pariz", 3},
+	}
+
+	for _, test := range tests {
+		matches := lookup.Destinations(test.query, 1)
+		if len(matches) == 0 || matches[0].Destination.CityId != test.cityId {
+			t.Errorf("%q: got %v, want city %d", test.query, matches, test.cityId)
+		}
+	}
+}
+
+func TestEditDistanceCountsRunes(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"москва", "москва", 0},
+		{"москва", "мaсква", 1},
+		{"москва", "мосвка", 1},
+		{"αθηνα", "αθινα", 1},
+		{"hotel", "hotle", 1},
+	}
+
+	for _, test := range tests {
+		if got := editDistance([]rune(test.a), []rune(test.b), 2); got != test.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestTokenizeFoldsDiacritics(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Αθήνα, Ελλάδα", []string{"αθηνα", "ελλαδα"}},
+		{"Ηράκλειο Κρήτης", []string{"ηρακλειο", "κρητης"}},
+		{"Crème Brûlée", []string{"creme", "brulee"}},
+		{"Łódź", []string{"lodz"}},
+		{"Straße", []string{"strasse"}},
+		{"Ålesund Øst", []string{"alesund", "ost"}},
+		{"Ханой Йошкар-Ола", []string{"ханои", "иошкар", "ола"}},
+		{"Cafe\u0301 ﬁve", []string{"cafe", "five"}},
+	}
+
+	for _, test := range tests {
+		if got := tokenize(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestLookupMatchesAccentedNamesExactly(t *testing.T) {
+	lookup := NewLookup([]*client.Destination{
+		{CityId: 1, City: "Αθήνα", Country: "Ελλάδα"},
+		{CityId: 2, City: "Ηράκλειο", Country: "Ελλάδα"},
+		{CityId: 3, City: "Besançon", Country: "France"},
+	}, nil)
+
+	tests := []struct {
+		query  string
+		cityId int64
+	}{
+		{"Αθηνα", 1},
+		{"αθήνα", 1},
+		{"ηρακλειο", 2},
+		{"Besancon", 3},
+	}
+
+	for _, test := range tests {
+		matches := lookup.Destinations(test.query, 1)
+		if len(matches) == 0 || matches[0].Destination.CityId != test.cityId || matches[0].Score != scoreExact*weightPrimary {
+			t.Errorf("%q: got %v, want exact match of city %d", test.query, matches, test.cityId)
+		}
+	}
+}
+
+func TestLookupRanksCandidatesByTrigrams(t *testing.T) {
+	var hotels []*client.Hotel
+	for i := 1; i <= 5000; i++ {
+		hotels = append(hotels, &client.Hotel{HotelID: int64(i), Name: fmt.Sprintf("Hotel Parkside %d", i), City: "London"})
+	}
+	hotels = append(hotels, &client.Hotel{HotelID: 9001, Name: "Hotel Paris", City: "Paris"})
+	lookup := NewLookup(nil, hotels)
+
+	//every hotel shares "hotel" and "par" with the query, the exact one has all its trigrams
+	matches := lookup.Hotels("hotel paris", 2)
+	if len(matches) != 2 || matches[0].Hotel.HotelID != 9001 || matches[1].Hotel.HotelID != 1 {
+		t.Errorf("got %v, want hotels 9001 and 1", MatchedHotelIds(matches))
+	}
+	if matches = lookup.Hotels("hotl pars", 1); len(matches) != 1 || matches[0].Hotel.HotelID != 9001 {
+		t.Errorf("got %v for a query with typos, want hotel 9001", MatchedHotelIds(matches))
+	}
+
+	if matches = lookup.Hotels("hotel", 10); len(matches) != 10 || matches[0].Score != scoreExact*weightPrimary {
+		t.Errorf("got %d matches of a common word, want 10 exact ones", len(matches))
+	}
+}
@@ -0,0 +1,440 @@
+package staticdata
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/DmitryKolbin/go-global/pkg/client"
+)
+
+const (
+	snapshotFileName = "snapshot.gob"
+	//DefaultMaxRemovedShare is used when Config.MaxRemovedShare is 0
+	DefaultMaxRemovedShare = 0.2
+)
+
+// ErrRefreshRejected is returned by Refresh when the downloaded dump would remove too many hotels,
+// usually an empty or truncated dump. The previous snapshot is kept, call ForceRefresh to accept a legitimate removal
+var ErrRefreshRejected = errors.New("staticdata: refresh rejected")
+
+// Source of static data, client.GoGlobalService satisfies it
+type Source interface {
+	StreamDestinations(context.Context, client.Credentials, func(*client.Destination) error) error
+	StreamHotels(context.Context, client.Credentials, func(*client.Hotel) error) error
+}
+
+type Config struct {
+	//Directory of the on-disk snapshot, created if missing
+	Dir string
+	//Credentials used to download the dumps
+	Credentials client.Credentials
+	//How often Run refreshes the snapshot
+	RefreshInterval time.Duration
+	//Called by Run after each refresh attempt
+	OnRefresh func(Diff, error)
+	//Max share of the current hotels a refresh may remove, 0 - DefaultMaxRemovedShare, >= 1 - no limit
+	MaxRemovedShare float64
+}
+
+// Diff is the difference between two consecutive hotel snapshots
+type Diff struct {
+	Added   []client.Hotel
+	Removed []client.Hotel
+	Changed []HotelChange
+	//Time of the new snapshot
+	RefreshedAt time.Time
+}
+
+type HotelChange struct {
+	Old client.Hotel
+	New client.Hotel
+}
+
+// IsEmpty reports whether the hotel list did not change
+func (d Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Store keeps Go Global hotels and destinations on disk and in memory with lookups by the common keys.
+// The supplier only provides full dumps, so every refresh downloads them and compares with the previous snapshot
+type Store struct {
+	config Config
+	source Source
+
+	//serializes refreshes
+	refreshMu sync.Mutex
+
+	mu       sync.RWMutex
+	snapshot *snapshot
+}
+
+type snapshot struct {
+	CreatedAt    time.Time
+	Hotels       []client.Hotel
+	Destinations []client.Destination
+
+	hotelById         map[int64]int
+	hotelsByCity      map[int64][]int
+	hotelsByGiataCode map[string][]int
+	hotelsByIsoCode   map[string][]int
+	destinationById   map[int64]int
+	destinationsByIso map[string][]int
+}
+
+// Open loads the snapshot saved in config.Dir, if there is one. Call Refresh or Run to download the data
+func Open(config Config, source Source) (*Store, error) {
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("staticdata: create dir: %w", err)
+	}
+
+	s := &Store{
+		config:   config,
+		source:   source,
+		snapshot: newSnapshot(time.Time{}, nil, nil),
+	}
+
+	loaded, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if loaded != nil {
+		s.snapshot = loaded
+	}
+
+	return s, nil
+}
+
+// UpdatedAt returns time of the current snapshot, zero if nothing was downloaded yet
+func (s *Store) UpdatedAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.snapshot.CreatedAt
+}
+
+// Run refreshes the snapshot every RefreshInterval until ctx is done.
+// The first refresh happens immediately when the snapshot is missing or outdated
+func (s *Store) Run(ctx context.Context) {
+	interval := s.config.RefreshInterval
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	if time.Since(s.UpdatedAt()) >= interval {
+		s.refreshAndNotify(ctx)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refreshAndNotify(ctx)
+		}
+	}
+}
+
+func (s *Store) refreshAndNotify(ctx context.Context) {
+	diff, err := s.Refresh(ctx)
+	if s.config.OnRefresh != nil {
+		s.config.OnRefresh(diff, err)
+	}
+}
+
+// Refresh downloads hotels and destinations, saves them to disk and returns the difference with the previous snapshot
+func (s *Store) Refresh(ctx context.Context) (Diff, error) {
+	return s.refresh(ctx, false)
+}
+
+// ForceRefresh is Refresh accepting the dump whatever share of hotels it removes. Use it after Refresh
+// returned ErrRefreshRejected for a removal known to be legitimate, next refreshes of Run compare with the forced snapshot
+func (s *Store) ForceRefresh(ctx context.Context) (Diff, error) {
+	return s.refresh(ctx, true)
+}
+
+func (s *Store) refresh(ctx context.Context, force bool) (Diff, error) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	var destinations []client.Destination
+	err := s.source.StreamDestinations(ctx, s.config.Credentials, func(destination *client.Destination) error {
+		destinations = append(destinations, *destination)
+		return nil
+	})
+	if err != nil {
+		return Diff{}, fmt.Errorf("staticdata: download destinations: %w", err)
+	}
+
+	var hotels []client.Hotel
+	err = s.source.StreamHotels(ctx, s.config.Credentials, func(hotel *client.Hotel) error {
+		hotels = append(hotels, *hotel)
+		return nil
+	})
+	if err != nil {
+		return Diff{}, fmt.Errorf("staticdata: download hotels: %w", err)
+	}
+
+	next := newSnapshot(time.Now(), hotels, destinations)
+
+	s.mu.RLock()
+	prev := s.snapshot
+	s.mu.RUnlock()
+
+	diff := diffSnapshots(prev, next)
+	if !force {
+		if err = s.checkRemoved(len(prev.Hotels), len(diff.Removed)); err != nil {
+			return Diff{}, err
+		}
+	}
+
+	if err = s.save(next); err != nil {
+		return Diff{}, err
+	}
+
+	s.mu.Lock()
+	s.snapshot = next
+	s.mu.Unlock()
+
+	return diff, nil
+}
+
+// checkRemoved rejects a refresh removing more than MaxRemovedShare of the current hotels
+func (s *Store) checkRemoved(current, removed int) error {
+	maxShare := s.config.MaxRemovedShare
+	if maxShare == 0 {
+		maxShare = DefaultMaxRemovedShare
+	}
+	if current == 0 || maxShare >= 1 {
+		return nil
+	}
+
+	if share := float64(removed) / float64(current); share > maxShare {
+		return fmt.Errorf("%w: %d of %d hotels would be removed, max share is %.2f",
+			ErrRefreshRejected, removed, current, maxShare)
+	}
+
+	return nil
+}
+
+// Hotel returns hotel by Go Global HotelID
+func (s *Store) Hotel(hotelId int64) (client.Hotel, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i, ok := s.snapshot.hotelById[hotelId]
+	if !ok {
+		return client.Hotel{}, false
+	}
+
+	return s.snapshot.Hotels[i], true
+}
+
+// HotelsByCity returns hotels of the Go Global city
+func (s *Store) HotelsByCity(cityId int64) []client.Hotel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.snapshot.hotels(s.snapshot.hotelsByCity[cityId])
+}
+
+// HotelsByGiataCode returns hotels mapped to the GIATA code, more than one means duplicates on Go Global side
+func (s *Store) HotelsByGiataCode(giataCode string) []client.Hotel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.snapshot.hotels(s.snapshot.hotelsByGiataCode[normalizeGiataCode(giataCode)])
+}
+
+// GiataMapping builds GIATA mapping of the current snapshot, build it again after Refresh
+func (s *Store) GiataMapping() *GiataMapping {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hotels := make([]*client.Hotel, 0, len(s.snapshot.Hotels))
+	for i := range s.snapshot.Hotels {
+		hotels = append(hotels, &s.snapshot.Hotels[i])
+	}
+
+	return NewGiataMapping(hotels)
+}
+
+// HotelsByIsoCode returns hotels of the country by ISO code
+func (s *Store) HotelsByIsoCode(isoCode string) []client.Hotel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.snapshot.hotels(s.snapshot.hotelsByIsoCode[isoCode])
+}
+
+// Hotels returns all hotels of the current snapshot
+func (s *Store) Hotels() []client.Hotel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]client.Hotel(nil), s.snapshot.Hotels...)
+}
+
+// Destination returns destination by CityId
+func (s *Store) Destination(cityId int64) (client.Destination, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i, ok := s.snapshot.destinationById[cityId]
+	if !ok {
+		return client.Destination{}, false
+	}
+
+	return s.snapshot.Destinations[i], true
+}
+
+// DestinationsByIsoCode returns destinations of the country by ISO code
+func (s *Store) DestinationsByIsoCode(isoCode string) []client.Destination {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	indexes := s.snapshot.destinationsByIso[isoCode]
+	res := make([]client.Destination, 0, len(indexes))
+	for _, i := range indexes {
+		res = append(res, s.snapshot.Destinations[i])
+	}
+
+	return res
+}
+
+// Destinations returns all destinations of the current snapshot
+func (s *Store) Destinations() []client.Destination {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]client.Destination(nil), s.snapshot.Destinations...)
+}
+
+func (s *Store) path() string {
+	return filepath.Join(s.config.Dir, snapshotFileName)
+}
+
+func (s *Store) load() (*snapshot, error) {
+	f, err := os.Open(s.path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("staticdata: open snapshot: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var loaded snapshot
+	if err = gob.NewDecoder(f).Decode(&loaded); err != nil {
+		return nil, fmt.Errorf("staticdata: decode snapshot: %w", err)
+	}
+
+	return newSnapshot(loaded.CreatedAt, loaded.Hotels, loaded.Destinations), nil
+}
+
+// save writes the snapshot to a temp file and renames it, so a crash never leaves a broken snapshot
+func (s *Store) save(snap *snapshot) error {
+	f, err := os.CreateTemp(s.config.Dir, snapshotFileName+".*")
+	if err != nil {
+		return fmt.Errorf("staticdata: create snapshot: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	if err = gob.NewEncoder(f).Encode(snap); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("staticdata: encode snapshot: %w", err)
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("staticdata: sync snapshot: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("staticdata: close snapshot: %w", err)
+	}
+
+	if err = os.Rename(f.Name(), s.path()); err != nil {
+		return fmt.Errorf("staticdata: replace snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// newSnapshot indexes hotels and destinations. The dumps may repeat a HotelID, only the first hotel with it is kept
+func newSnapshot(createdAt time.Time, hotels []client.Hotel, destinations []client.Destination) *snapshot {
+	snap := &snapshot{
+		CreatedAt:         createdAt,
+		Hotels:            make([]client.Hotel, 0, len(hotels)),
+		Destinations:      destinations,
+		hotelById:         make(map[int64]int, len(hotels)),
+		hotelsByCity:      map[int64][]int{},
+		hotelsByGiataCode: map[string][]int{},
+		hotelsByIsoCode:   map[string][]int{},
+		destinationById:   make(map[int64]int, len(destinations)),
+		destinationsByIso: map[string][]int{},
+	}
+
+	for _, hotel := range hotels {
+		if _, ok := snap.hotelById[hotel.HotelID]; ok {
+			continue
+		}
+		i := len(snap.Hotels)
+		snap.Hotels = append(snap.Hotels, hotel)
+		snap.hotelById[hotel.HotelID] = i
+		snap.hotelsByCity[hotel.CityId] = append(snap.hotelsByCity[hotel.CityId], i)
+		if code := normalizeGiataCode(hotel.GiataCode); code != "" {
+			snap.hotelsByGiataCode[code] = append(snap.hotelsByGiataCode[code], i)
+		}
+		snap.hotelsByIsoCode[hotel.IsoCode] = append(snap.hotelsByIsoCode[hotel.IsoCode], i)
+	}
+
+	for i, destination := range destinations {
+		snap.destinationById[destination.CityId] = i
+		snap.destinationsByIso[destination.IsoCode] = append(snap.destinationsByIso[destination.IsoCode], i)
+	}
+
+	return snap
+}
+
+func (snap *snapshot) hotels(indexes []int) []client.Hotel {
+	res := make([]client.Hotel, 0, len(indexes))
+	for _, i := range indexes {
+		res = append(res, snap.Hotels[i])
+	}
+
+	return res
+}
+
+func diffSnapshots(prev, next *snapshot) Diff {
+	diff := Diff{RefreshedAt: next.CreatedAt}
+
+	for _, hotel := range next.Hotels {
+		i, ok := prev.hotelById[hotel.HotelID]
+		if !ok {
+			diff.Added = append(diff.Added, hotel)
+			continue
+		}
+		if old := prev.Hotels[i]; old != hotel {
+			diff.Changed = append(diff.Changed, HotelChange{Old: old, New: hotel})
+		}
+	}
+
+	for _, hotel := range prev.Hotels {
+		if _, ok := next.hotelById[hotel.HotelID]; !ok {
+			diff.Removed = append(diff.Removed, hotel)
+		}
+	}
+
+	return diff
+}
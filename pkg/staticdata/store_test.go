@@ -0,0 +1,138 @@
+package staticdata
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DmitryKolbin/go-global/pkg/client"
+)
+
+type fakeSource struct {
+	hotels []client.Hotel
+}
+
+func (s *fakeSource) StreamDestinations(context.Context, client.Credentials, func(*client.Destination) error) error {
+	return nil
+}
+
+func (s *fakeSource) StreamHotels(_ context.Context, _ client.Credentials, fn func(*client.Hotel) error) error {
+	for i := range s.hotels {
+		if err := fn(&s.hotels[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hotelsWithIds(ids ...int64) []client.Hotel {
+	hotels := make([]client.Hotel, 0, len(ids))
+	for _, id := range ids {
+		hotels = append(hotels, client.Hotel{HotelID: id})
+	}
+
+	return hotels
+}
+
+func TestRefreshRejectsRemovingTooManyHotels(t *testing.T) {
+	source := &fakeSource{hotels: hotelsWithIds(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)}
+	store, err := Open(Config{Dir: t.TempDir()}, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		hotels []client.Hotel
+		err    error
+		count  int
+	}{
+		{name: "empty dump", hotels: nil, err: ErrRefreshRejected, count: 10},
+		{name: "truncated dump", hotels: hotelsWithIds(1, 2, 3), err: ErrRefreshRejected, count: 10},
+		{name: "two removed", hotels: hotelsWithIds(1, 2, 3, 4, 5, 6, 7, 8, 11), err: nil, count: 9},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			source.hotels = test.hotels
+			_, err := store.Refresh(context.Background())
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+			if count := len(store.Hotels()); count != test.count {
+				t.Errorf("got %d hotels, want %d", count, test.count)
+			}
+		})
+	}
+}
+
+func TestRefreshDedupesHotelIds(t *testing.T) {
+	source := &fakeSource{hotels: hotelsWithIds(1, 2)}
+	store, err := Open(Config{Dir: t.TempDir()}, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	source.hotels = []client.Hotel{
+		{HotelID: 1, Name: "Renamed"},
+		{HotelID: 1, Name: "Repeated"},
+		{HotelID: 2},
+		{HotelID: 3},
+		{HotelID: 3, Name: "Repeated"},
+	}
+	diff, err := store.Refresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diff.Added) != 1 || diff.Added[0] != (client.Hotel{HotelID: 3}) {
+		t.Errorf("got added %+v, want hotel 3 once", diff.Added)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].New.Name != "Renamed" {
+		t.Errorf("got changed %+v, want the first hotel 1 once", diff.Changed)
+	}
+	if count := len(store.Hotels()); count != 3 {
+		t.Errorf("got %d hotels, want 3", count)
+	}
+
+	//the next refresh of the same dump finds no changes
+	if diff, err = store.Refresh(context.Background()); err != nil || !diff.IsEmpty() {
+		t.Errorf("got %+v, %v, want an empty diff", diff, err)
+	}
+}
+
+func TestForceRefreshSkipsRemovedShare(t *testing.T) {
+	source := &fakeSource{hotels: hotelsWithIds(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)}
+	store, err := Open(Config{Dir: t.TempDir()}, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	source.hotels = hotelsWithIds(1, 2, 3)
+	if _, err = store.Refresh(context.Background()); !errors.Is(err, ErrRefreshRejected) {
+		t.Fatalf("got error %v, want %v", err, ErrRefreshRejected)
+	}
+
+	diff, err := store.ForceRefresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Removed) != 7 || len(store.Hotels()) != 3 {
+		t.Errorf("got %d removed and %d hotels left, want 7 and 3", len(diff.Removed), len(store.Hotels()))
+	}
+
+	//later refreshes compare with the forced snapshot
+	if diff, err = store.Refresh(context.Background()); err != nil || !diff.IsEmpty() {
+		t.Errorf("got %+v, %v, want an empty diff", diff, err)
+	}
+}